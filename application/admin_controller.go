@@ -0,0 +1,36 @@
+package application
+
+import (
+	"crypto/subtle"
+	"ifttt/handler/application/config"
+	"ifttt/handler/common"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newAdminController(router fiber.Router, core *ServerCore) {
+	admin := router.Group("/admin", adminAuth(config.GetConfigProp(common.EnvAdminToken)))
+	admin.Get("/internalTags", func(c *fiber.Ctx) error {
+		return c.JSON(core.InternalTags)
+	})
+	admin.Get("/events", func(c *fiber.Ctx) error {
+		return c.JSON(core.Events)
+	})
+}
+
+// adminAuth only lets requests through with the configured bearer token,
+// admin routes are not served when no token is configured
+func adminAuth(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token == "" {
+			return c.SendStatus(http.StatusNotFound)
+		}
+		provided, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			return c.SendStatus(http.StatusUnauthorized)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,272 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"ifttt/handler/common"
+	"ifttt/handler/domain/orm_schema"
+	infraStore "ifttt/handler/infrastructure/store"
+	"os"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+const (
+	commandIntrospect = "introspect"
+	commandMigrate    = "migrate"
+)
+
+func RunCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no command provided")
+	}
+
+	switch args[0] {
+	case commandIntrospect:
+		return runIntrospect(args[1:])
+	case commandMigrate:
+		return runMigrate(args[1:])
+	default:
+		return fmt.Errorf("command %s not found", args[0])
+	}
+}
+
+func runIntrospect(args []string) error {
+	flags := flag.NewFlagSet(commandIntrospect, flag.ContinueOnError)
+	source := flags.String("source", common.DataSourceDefault, "data store to introspect")
+	tables := flags.String("tables", "", "comma separated tables to include")
+	out := flags.String("out", "", "write the model bundle to this file instead of the config store")
+	overwrite := flags.Bool("overwrite", false, "replace models and associations that already exist in the config store")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	dataStore, err := commandDataStore(*source)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	schema, err := dataStore.SchemaRepo.IntrospectSchema(ctx)
+	if err != nil {
+		return fmt.Errorf("could not introspect %s: %s", *source, err)
+	}
+
+	var tableFilter []string
+	if *tables != "" {
+		tableFilter = strings.Split(*tables, ",")
+	}
+	bundle, err := orm_schema.ProposeModels(schema, tableFilter)
+	if err != nil {
+		return err
+	}
+	for _, skipped := range bundle.Skipped {
+		fmt.Printf("skipped %s\n", skipped)
+	}
+
+	if *out != "" {
+		marshalled, err := json.MarshalIndent(bundle, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*out, marshalled, 0644); err != nil {
+			return fmt.Errorf("could not write bundle: %s", err)
+		}
+		fmt.Printf("wrote %d models and %d associations to %s\n",
+			len(bundle.Models), len(bundle.Associations), *out)
+		return nil
+	}
+
+	configStore, err := infraStore.NewConfigStore()
+	if err != nil {
+		return err
+	}
+	kept, err := configStore.OrmRepo.SaveModels(bundle, *overwrite)
+	if err != nil {
+		return fmt.Errorf("could not save models: %s", err)
+	}
+	for _, existing := range kept {
+		fmt.Printf("kept existing %s, pass -overwrite to replace it\n", existing)
+	}
+	fmt.Printf("saved %d of %d models and associations\n",
+		len(bundle.Models)+len(bundle.Associations)-len(kept), len(bundle.Models)+len(bundle.Associations))
+	return nil
+}
+
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet(commandMigrate, flag.ContinueOnError)
+	source := flags.String("source", common.DataSourceDefault, "data store to migrate")
+	models := flags.String("models", "", "comma separated models to include")
+	dryRun := flags.Bool("dry-run", false, "print the statements without applying them")
+	out := flags.String("out", "", "write the migration plan to this file")
+	alter := flags.Bool("alter", false, "apply statements that change the type or nullability of existing columns")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	dataStore, err := commandDataStore(*source)
+	if err != nil {
+		return err
+	}
+	configStore, err := infraStore.NewConfigStore()
+	if err != nil {
+		return err
+	}
+
+	allModels, err := configStore.OrmRepo.GetAllModels()
+	if err != nil {
+		return fmt.Errorf("could not get models: %s", err)
+	}
+	associations, err := configStore.OrmRepo.GetAllAssociations()
+	if err != nil {
+		return fmt.Errorf("could not get associations: %s", err)
+	}
+
+	selected := []orm_schema.Model{}
+	tables := map[string]bool{}
+	if allModels != nil {
+		var modelFilter []string
+		if *models != "" {
+			modelFilter = strings.Split(*models, ",")
+		}
+		for _, m := range *allModels {
+			if len(modelFilter) == 0 || lo.ContainsBy(modelFilter, func(name string) bool {
+				return strings.EqualFold(name, m.Name)
+			}) {
+				selected = append(selected, m)
+				tables[m.Table] = true
+			}
+		}
+	}
+	selectedAssociations := []orm_schema.ModelAssociation{}
+	if associations != nil {
+		for _, a := range *associations {
+			if tables[a.TableName] || tables[a.ReferencesTable] {
+				selectedAssociations = append(selectedAssociations, a)
+			}
+		}
+	}
+
+	ctx := context.Background()
+	schema, err := dataStore.SchemaRepo.IntrospectSchema(ctx)
+	if err != nil {
+		return fmt.Errorf("could not introspect %s: %s", *source, err)
+	}
+
+	dialect := dataStore.RawQueryRepo.Dialect()
+	plan, err := orm_schema.PlanMigration(dialect, selected, selectedAssociations, schema)
+	if err != nil {
+		return err
+	}
+	for _, warning := range plan.Warnings {
+		fmt.Printf("warning: %s\n", warning)
+	}
+
+	if *out != "" {
+		marshalled, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*out, marshalled, 0644); err != nil {
+			return fmt.Errorf("could not write plan: %s", err)
+		}
+	}
+
+	if len(plan.Statements) == 0 {
+		fmt.Printf("%s is up to date\n", *source)
+		return nil
+	}
+	if *dryRun {
+		for _, statement := range plan.Statements {
+			if statement.Alter && !*alter {
+				fmt.Printf("-- %s (requires -alter)\n%s;\n\n", statement.Description, statement.Statement)
+			} else {
+				fmt.Printf("-- %s\n%s;\n\n", statement.Description, statement.Statement)
+			}
+		}
+		return nil
+	}
+
+	statements := []orm_schema.MigrationStatement{}
+	for _, statement := range plan.Statements {
+		if statement.Alter && !*alter {
+			fmt.Printf("skipped %s, pass -alter to apply it\n", statement.Description)
+			continue
+		}
+		statements = append(statements, statement)
+	}
+	if len(statements) == 0 {
+		return nil
+	}
+
+	if dialect == common.DialectMySQL {
+		return applyStatementwise(dataStore, statements, *source, ctx)
+	}
+
+	tx, err := dataStore.RawQueryRepo.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin tx: %s", err)
+	}
+	for _, statement := range statements {
+		if _, err := dataStore.RawQueryRepo.Exec(tx, statement.Statement, nil, ctx); err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return fmt.Errorf("could not %s: %s. rollback failed %s", statement.Description, err, rollbackErr)
+			}
+			return fmt.Errorf("could not %s: %s", statement.Description, err)
+		}
+		fmt.Println(statement.Description)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit migration: %s", err)
+	}
+	fmt.Printf("applied %d statements to %s\n", len(statements), *source)
+	return nil
+}
+
+// applyStatementwise commits every statement on its own, mysql commits ddl
+// implicitly so a failure cannot roll back the statements before it
+func applyStatementwise(
+	dataStore *infraStore.DataStore, statements []orm_schema.MigrationStatement, source string, ctx context.Context,
+) error {
+	for idx, statement := range statements {
+		tx, err := dataStore.RawQueryRepo.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("could not begin tx: %s. applied %d of %d statements", err, idx, len(statements))
+		}
+		if _, err := dataStore.RawQueryRepo.Exec(tx, statement.Statement, nil, ctx); err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return fmt.Errorf("could not %s: %s. rollback failed %s. applied %d of %d statements, earlier statements are not rolled back",
+					statement.Description, err, rollbackErr, idx, len(statements))
+			}
+			return fmt.Errorf("could not %s: %s. applied %d of %d statements, earlier statements are not rolled back",
+				statement.Description, err, idx, len(statements))
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("could not commit %s: %s. applied %d of %d statements",
+				statement.Description, err, idx, len(statements))
+		}
+		fmt.Println(statement.Description)
+	}
+	fmt.Printf("applied %d statements to %s\n", len(statements), source)
+	return nil
+}
+
+func commandDataStore(name string) (*infraStore.DataStore, error) {
+	defaultStore, err := infraStore.NewDataStore()
+	if err != nil {
+		return nil, err
+	}
+	dataStores, err := infraStore.NewDataStores(defaultStore)
+	if err != nil {
+		return nil, err
+	}
+
+	dataStore, ok := dataStores[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("data store %s not found", name)
+	}
+	return dataStore, nil
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"ifttt/handler/common"
 	"ifttt/handler/domain/api"
+	"ifttt/handler/domain/configuration"
+	requestvalidator "ifttt/handler/domain/request_validator.go"
 	"ifttt/handler/domain/resolvable"
 	infraStore "ifttt/handler/infrastructure/store"
 	"net"
@@ -20,9 +22,13 @@ type ServerCore struct {
 	Cron                   *cron.Cron
 	ConfigStore            *infraStore.ConfigStore
 	DataStore              *infraStore.DataStore
+	DataStores             map[string]*infraStore.DataStore
 	CacheStore             *infraStore.CacheStore
 	AppCacheStore          *infraStore.AppCacheStore
 	ResolvableDependencies map[common.IntIota]any
+	InternalTags           []configuration.InternalTagUsage
+	Events                 []configuration.Event
+	RequestSchemas         map[string]*requestvalidator.JSONSchema
 	Logger                 *logrus.Logger
 	selfClient             *fasthttp.Client
 }
@@ -41,6 +47,11 @@ func newServerCore() (*ServerCore, error) {
 	} else {
 		serverCore.DataStore = dataStore
 	}
+	if dataStores, err := infraStore.NewDataStores(serverCore.DataStore); err != nil {
+		return nil, err
+	} else {
+		serverCore.DataStores = dataStores
+	}
 	if cacheStore, err := infraStore.NewCacheStore(); err != nil {
 		return nil, err
 	} else {
@@ -58,8 +69,17 @@ func newServerCore() (*ServerCore, error) {
 	}
 	logger := common.CreateLogrus()
 	serverCore.Logger = logger
+	dataSources := make(map[string]*resolvable.DataSource, len(serverCore.DataStores))
+	for name, dataStore := range serverCore.DataStores {
+		dataSources[name] = &resolvable.DataSource{
+			Name:         name,
+			RawQueryRepo: dataStore.RawQueryRepo,
+			Timeout:      dataStore.Timeout,
+		}
+	}
 	serverCore.ResolvableDependencies = map[common.IntIota]any{
 		common.DependencyRawQueryRepo: serverCore.DataStore.RawQueryRepo,
+		common.DependencyDataSources:  dataSources,
 		common.DependencyAppCacheRepo: serverCore.AppCacheStore.AppCacheRepo,
 		common.DependencyOrmCacheRepo: serverCore.CacheStore.OrmRepo,
 	}
@@ -90,7 +90,6 @@ func mainController(core *ServerCore, parentCtx context.Context) func(c *fiber.C
 			} else {
 				tracerStr := tracer.String()
 				c.Set(common.ResponseHeaderTracer, tracerStr)
-				c.Set(common.ResponseHeaderContentType, "application/json")
 				contextState.Store(common.ContextTracer, tracerStr)
 				common.LogWithTracer(common.LogSystem, fmt.Sprintf(
 					"Request recieved: %s | Start time: %s", c.Path(), logData.Start.String(),
@@ -140,17 +139,16 @@ func mainController(core *ServerCore, parentCtx context.Context) func(c *fiber.C
 				}, false, ctx)
 
 				contextState.Store(common.ContextLogStage, common.LogStageValidation)
-				if vErr := requestvalidator.ValidateMap(&api.Request, reqBody, scanToInternal); len(vErr) != 0 {
+				var vErr []requestvalidator.ValidationError
+				if api.RequestSchema != nil {
+					vErr = requestvalidator.ValidateJSONSchema(core.RequestSchemas[api.Path], reqBody, scanToInternal)
+				} else {
+					vErr = requestvalidator.ValidateMap(&api.Request, reqBody, scanToInternal)
+				}
+				if len(vErr) != 0 {
 					defer cancel(nil)
-					err := requestvalidator.Normalize(vErr)
-					requestData.AddErrors(err...)
-					strErr := make([]string, 0, len(err))
-					for _, e := range err {
-						if str := e.Error(); str != "" {
-							strErr = append(strErr, str)
-						}
-					}
-					scanToInternal(common.InternalTagErrorValidation, strErr)
+					requestData.AddErrors(requestvalidator.Normalize(vErr)...)
+					scanToInternal(common.InternalTagErrorValidation, requestvalidator.Details(vErr))
 					common.LogWithTracer(common.LogSystem, "request validation failed", vErr, false, ctx)
 					response := &resolvable.Response{Event: common.EventCodes[common.EventBadRequest]}
 					response.ChannelSend(responseChan, ctx)
@@ -180,7 +178,9 @@ func mainController(core *ServerCore, parentCtx context.Context) func(c *fiber.C
 
 			responseEvent := common.EventCodes[common.EventExhaust]
 			err = context.Cause(ctx)
-			if err != nil {
+			if _, raised := contextState.Load(common.ContextErrorRaised); raised {
+				requestData.AddErrors(err)
+			} else if err != nil {
 				responseEvent = common.EventCodes[common.EventSystemMalfunction]
 				requestData.AddErrors(err)
 				requestData.SetStore(common.InternalTagErrorSystem, err.Error())
@@ -190,10 +190,36 @@ func mainController(core *ServerCore, parentCtx context.Context) func(c *fiber.C
 		}(cancelCtx)
 
 		res := <-responseChan
-		if response, status, err := res.HandlerEvent(valueCtx, core.ResolvableDependencies); err != nil {
-			return c.Status(status).JSON(common.ResponseDefaultMalfunction)
-		} else {
-			return c.Status(status).JSON(response)
+		rendered, err := res.HandlerEvent(valueCtx, core.ResolvableDependencies)
+		if err != nil {
+			common.LogWithTracer(common.LogSystem, "could not render response", err, true, valueCtx)
+			c.Set(common.ResponseHeaderContentType, "application/json")
+			return c.Status(http.StatusInternalServerError).JSON(common.ResponseDefaultMalfunction)
 		}
+		return writeResponse(c, rendered)
+	}
+}
+
+func writeResponse(c *fiber.Ctx, rendered *resolvable.RenderedResponse) error {
+	for name, value := range rendered.Headers {
+		c.Set(name, value)
+	}
+	for _, cookie := range rendered.Cookies {
+		c.Cookie(&fiber.Cookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Path:     cookie.Path,
+			Domain:   cookie.Domain,
+			MaxAge:   cookie.MaxAge,
+			Secure:   cookie.Secure,
+			HTTPOnly: cookie.HTTPOnly,
+			SameSite: cookie.SameSite,
+		})
+	}
+
+	if rendered.Redirect != "" {
+		return c.Redirect(rendered.Redirect, rendered.StatusCode)
 	}
+	c.Set(common.ResponseHeaderContentType, rendered.ContentType)
+	return c.Status(rendered.StatusCode).Send(rendered.Body)
 }
@@ -6,7 +6,10 @@ import (
 	"ifttt/handler/application/config"
 	"ifttt/handler/common"
 	"ifttt/handler/domain/api"
+	"ifttt/handler/domain/configuration"
 	"ifttt/handler/domain/orm_schema"
+	requestvalidator "ifttt/handler/domain/request_validator.go"
+	"ifttt/handler/domain/resolvable"
 	"net"
 	"os"
 	"strings"
@@ -35,6 +38,8 @@ func Init() {
 		panic(err)
 	}
 
+	newAdminController(app, currCore)
+
 	currCore.Logger.Info("getting and storing models")
 	if err := orm_schema.GetAndStoreModels(
 		currCore.ConfigStore.OrmRepo, currCore.CacheStore.OrmRepo, ctx,
@@ -104,6 +109,40 @@ func createApis(fiber *fiber.App, ctx context.Context) error {
 		return fmt.Errorf("could not attach response profiles to apis: %s", err)
 	}
 
+	if err := api.CompileRequestSchemas(apis); err != nil {
+		return err
+	}
+	currCore.RequestSchemas = map[string]*requestvalidator.JSONSchema{}
+	if apis != nil {
+		for _, a := range *apis {
+			if a.CompiledRequestSchema != nil {
+				currCore.RequestSchemas[a.Path] = a.CompiledRequestSchema
+			}
+		}
+	}
+
+	events, err := currCore.ConfigStore.EventRepo.GetAllEvents()
+	if err != nil {
+		return fmt.Errorf("could not get events: %s", err)
+	}
+	if currCore.Events, err = configuration.MergeEvents(events); err != nil {
+		return err
+	}
+
+	internalTags, err := currCore.ConfigStore.InternalTagRepo.GetAllInternalTags()
+	if err != nil {
+		return fmt.Errorf("could not get internal tags: %s", err)
+	}
+	if currCore.InternalTags, err = api.ValidateInternalTags(apis, profiles, currCore.Events, internalTags); err != nil {
+		return err
+	}
+
+	fallbacks := resolvable.EventFallbacks(currCore.Events)
+	currCore.ResolvableDependencies[common.DependencyEventFallbacks] = fallbacks
+	for _, missing := range api.MissingEventDefinitions(apis, fallbacks) {
+		currCore.Logger.Warn(missing)
+	}
+
 	if err := currCore.CacheStore.APIRepo.StoreApis(apis, ctx); err != nil {
 		return fmt.Errorf("could not store apis in cache storage: %s", err)
 	}
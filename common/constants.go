@@ -11,9 +11,11 @@ const (
 	DependencyLogger
 	DependencyOrmCacheRepo
 	DependencyOrmQueryRepo
+	DependencyDataSources
+	DependencyEventFallbacks
 )
 
-var ReservedPaths = []string{"^/test/.*"}
+var ReservedPaths = []string{"^/test/.*", "^/admin/.*"}
 
 const (
 	ContextState IntIota = iota
@@ -26,6 +28,8 @@ const (
 	ContextLogStage
 	ContextIter
 	ContextResponseProfiles
+	ContextTransaction
+	ContextErrorRaised
 )
 
 const (
@@ -69,12 +73,14 @@ const (
 	EventSystemMalfunction
 	EventNotFound
 	EventBadRequest
+	EventUserError
 )
 
 var EventCodes = map[IntIota]uint{
 	EventSuccess:           0,
 	EventExhaust:           10,
 	EventBadRequest:        400,
+	EventUserError:         422,
 	EventNotFound:          404,
 	EventSystemMalfunction: 500,
 }
@@ -82,6 +88,15 @@ var EventCodes = map[IntIota]uint{
 const (
 	ResponseHeaderTracer      = "tracer"
 	ResponseHeaderContentType = "Content-Type"
+	ResponseHeaderDisposition = "Content-Disposition"
+)
+
+const (
+	ResponseFormatJSON   = "json"
+	ResponseFormatXML    = "xml"
+	ResponseFormatText   = "text"
+	ResponseFormatCSV    = "csv"
+	ResponseFormatBinary = "binary"
 )
 
 const (
@@ -106,17 +121,54 @@ const (
 const (
 	EnvConfig      = "configStore"
 	EnvData        = "dataStore"
+	EnvDataStores  = "dataStores"
 	EnvCache       = "cacheStore"
 	EnvAppCache    = "appCache"
 	EnvConfigStore = "cacheStore"
 	EnvDBName      = "db"
+	EnvAdminToken  = "app.adminToken"
+)
+
+const (
+	OrmSelect    = "SELECT"
+	OrmUpdate    = "UPDATE"
+	OrmInsert    = "INSERT"
+	OrmDelete    = "DELETE"
+	OrmAggregate = "AGGREGATE"
 )
 
 const (
-	OrmSelect = "SELECT"
-	OrmUpdate = "UPDATE"
-	OrmInsert = "INSERT"
-	OrmDelete = "DELETE"
+	AggregateCount = "count"
+	AggregateSum   = "sum"
+	AggregateAvg   = "avg"
+	AggregateMin   = "min"
+	AggregateMax   = "max"
+)
+
+const (
+	OrmColumnDeletedAt = "deleted_at"
+	OrmColumnCreatedAt = "created_at"
+	OrmColumnUpdatedAt = "updated_at"
+)
+
+const DataSourceDefault = "default"
+
+const (
+	DialectMySQL    = "mysql"
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+const (
+	SortAscending  = "asc"
+	SortDescending = "desc"
+)
+
+const (
+	TxIsolationReadUncommitted = "readUncommitted"
+	TxIsolationReadCommitted   = "readCommitted"
+	TxIsolationRepeatableRead  = "repeatableRead"
+	TxIsolationSerializable    = "serializable"
 )
 
 const (
@@ -152,6 +204,24 @@ const SelfSocket = "/tmp/ifttt.sock"
 
 const AppCacheDefaultTTL = 86400
 
+const (
+	ApiDecodeAuto   = "auto"
+	ApiDecodeJSON   = "json"
+	ApiDecodeXML    = "xml"
+	ApiDecodeText   = "text"
+	ApiDecodeBinary = "binary"
+)
+
+const ApiDefaultMaxResponseSize = 10 << 20
+
+const (
+	ApiAuthOAuth2ClientCredentials = "oauth2ClientCredentials"
+	ApiAuthCachePrefix             = "oauth2"
+	ApiAuthDefaultRefreshBefore    = 60
+	ApiAuthDefaultTokenTTL         = 300
+	ApiAuthDefaultTimeout          = 10000
+)
+
 const (
 	ComparatorEquals            = "eq"
 	ComparatorNotEquals         = "ne"
@@ -163,6 +233,14 @@ const (
 	ComparatorGreaterThanEquals = "gte"
 )
 
+const (
+	WhereConditionAnd    = "AND"
+	WhereConditionOr     = "OR"
+	WhereOperatorLike    = "like"
+	WhereOperatorNull    = "null"
+	WhereOperatorNotNull = "notNull"
+)
+
 const (
 	ComparisionTypeString  = "string"
 	ComparisionTypeNumber  = "number"
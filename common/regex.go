@@ -6,6 +6,7 @@ var (
 	RegexPositionalParameters          = regexp.MustCompile(`\?`)
 	RegexNamedParameters               = regexp.MustCompile(`@\w+`)
 	RegexStringInterpolationParameters = regexp.MustCompile(`\$param`)
+	RegexSqlIdentifier                 = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
 	RegexEndpoint                      = regexp.MustCompile(`^\/([a-zA-Z0-9-_]+\/?)*$`)
 	RegexCron                          = regexp.MustCompile(`(@(annually|yearly|monthly|weekly|daily|hourly|reboot))|(@every (\d+(ns|us|µs|ms|s|m|h))+)|((((\d+,)+\d+|(\d+(\/|-)\d+)|\d+|\*) ?){5,7})`)
 )
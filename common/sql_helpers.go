@@ -0,0 +1,94 @@
+package common
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func SqlPlaceholder(dialect string, position int) string {
+	switch dialect {
+	case DialectPostgres:
+		return "$" + strconv.Itoa(position)
+	default:
+		return "?"
+	}
+}
+
+func SqlQuoteIdentifier(dialect string, identifier string) string {
+	switch dialect {
+	case DialectMySQL:
+		return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+	default:
+		return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+	}
+}
+
+func SqlKeysetCondition(
+	columns []string, descending []bool, values []any, placeholder func(value any) string,
+) string {
+	disjunctions := make([]string, 0, len(columns))
+	for i := range columns {
+		conjunctions := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			conjunctions = append(conjunctions, fmt.Sprintf("%s = %s", columns[j], placeholder(values[j])))
+		}
+		operator := ">"
+		if descending[i] {
+			operator = "<"
+		}
+		conjunctions = append(conjunctions, fmt.Sprintf("%s %s %s", columns[i], operator, placeholder(values[i])))
+		disjunctions = append(disjunctions, "("+strings.Join(conjunctions, " AND ")+")")
+	}
+	return "(" + strings.Join(disjunctions, " OR ") + ")"
+}
+
+func ScanRows(rows *sql.Rows, fn func(row map[string]any) error) (int, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		count        int
+		strDescision = make(map[int]bool, len(columns))
+	)
+	for rows.Next() {
+		scanCols := make([]any, len(columns))
+		for idx := range scanCols {
+			var a any
+			scanCols[idx] = &a
+		}
+		if err := rows.Scan(scanCols...); err != nil {
+			return count, err
+		}
+
+		m := make(map[string]any, len(columns))
+		for idx, v := range scanCols {
+			key := columns[idx]
+			retrieved := v.(*any)
+			if count == 0 {
+				if *retrieved == nil {
+					strDescision[idx] = true
+					m[key] = nil
+				} else if v, ok := (*retrieved).([]byte); ok {
+					strDescision[idx] = true
+					m[key] = string(v)
+				} else {
+					m[key] = *retrieved
+				}
+			} else if strcast, ok := (*retrieved).([]byte); ok && strDescision[idx] {
+				m[key] = string(strcast)
+			} else {
+				m[key] = *retrieved
+			}
+		}
+		count++
+
+		if err := fn(m); err != nil {
+			return count, err
+		}
+	}
+	return count, rows.Err()
+}
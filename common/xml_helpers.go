@@ -0,0 +1,108 @@
+package common
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	XmlAttributePrefix = "@"
+	XmlTextKey         = "#text"
+)
+
+type xmlNode struct {
+	name     string
+	attrs    map[string]any
+	children map[string]any
+	text     strings.Builder
+}
+
+func XmlToMap(data []byte) (map[string]any, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	var stack []*xmlNode
+	var root map[string]any
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("could not decode xml: %s", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{
+				name:     t.Name.Local,
+				attrs:    make(map[string]any, len(t.Attr)),
+				children: make(map[string]any),
+			}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+					continue
+				}
+				node.attrs[XmlAttributePrefix+attr.Name.Local] = attr.Value
+			}
+			stack = append(stack, node)
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("could not decode xml: unexpected end element %s", t.Name.Local)
+			}
+			node := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			value := node.value()
+			if len(stack) == 0 {
+				root = map[string]any{node.name: value}
+			} else {
+				stack[len(stack)-1].addChild(node.name, value)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("could not decode xml: no root element")
+	}
+	return root, nil
+}
+
+func (n *xmlNode) addChild(name string, value any) {
+	existing, ok := n.children[name]
+	if !ok {
+		n.children[name] = value
+	} else if arr, ok := existing.([]any); ok {
+		n.children[name] = append(arr, value)
+	} else {
+		n.children[name] = []any{existing, value}
+	}
+}
+
+func (n *xmlNode) value() any {
+	text := strings.TrimSpace(n.text.String())
+	if len(n.attrs) == 0 && len(n.children) == 0 {
+		return text
+	}
+
+	m := make(map[string]any, len(n.attrs)+len(n.children)+1)
+	for k, v := range n.attrs {
+		m[k] = v
+	}
+	for k, v := range n.children {
+		m[k] = v
+	}
+	if text != "" {
+		m[XmlTextKey] = text
+	}
+	return m
+}
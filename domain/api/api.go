@@ -13,15 +13,18 @@ type Cron struct {
 }
 
 type Api struct {
-	ID          uint                                         `json:"id" mapstructure:"id"`
-	Name        string                                       `json:"name" mapstructure:"name"`
-	Path        string                                       `json:"path" mapstructure:"path"`
-	Method      string                                       `json:"method" mapstructure:"method"`
-	Description string                                       `json:"description" mapstructure:"description"`
-	PreConfig   []resolvable.Resolvable                      `json:"preConfig" mapstructure:"preConfig"`
-	Request     map[string]requestvalidator.RequestParameter `json:"request" mapstructure:"request"`
-	Response    map[uint]resolvable.ResponseDefinition       `json:"response" mapstructure:"response"`
-	Triggers    *[]TriggerCondition                          `json:"triggers" mapstructure:"triggers"`
+	ID            uint                                         `json:"id" mapstructure:"id"`
+	Name          string                                       `json:"name" mapstructure:"name"`
+	Path          string                                       `json:"path" mapstructure:"path"`
+	Method        string                                       `json:"method" mapstructure:"method"`
+	Description   string                                       `json:"description" mapstructure:"description"`
+	PreConfig     []resolvable.Resolvable                      `json:"preConfig" mapstructure:"preConfig"`
+	Request       map[string]requestvalidator.RequestParameter `json:"request" mapstructure:"request"`
+	RequestSchema map[string]any                               `json:"requestSchema" mapstructure:"requestSchema"`
+	Response      map[uint]resolvable.ResponseDefinition       `json:"response" mapstructure:"response"`
+	Triggers      *[]TriggerCondition                          `json:"triggers" mapstructure:"triggers"`
+	// compiled from RequestSchema at load, it is not cached with the api
+	CompiledRequestSchema *requestvalidator.JSONSchema `json:"-" mapstructure:"-"`
 }
 
 type TriggerCondition struct {
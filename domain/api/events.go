@@ -0,0 +1,51 @@
+package api
+
+import (
+	"fmt"
+	"ifttt/handler/common"
+	"ifttt/handler/domain/resolvable"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+func (a *Api) EmittedEvents() []uint {
+	events := []uint{
+		common.EventCodes[common.EventExhaust],
+		common.EventCodes[common.EventSystemMalfunction],
+	}
+	if !strings.EqualFold(a.Method, http.MethodGet) {
+		events = append(events, common.EventCodes[common.EventBadRequest])
+	}
+	events = append(events, resolvable.CollectResponseEvents(a.PreConfig)...)
+	events = append(events, resolvable.CollectResponseEvents(a.Triggers)...)
+
+	events = lo.Uniq(events)
+	sort.Slice(events, func(i, j int) bool { return events[i] < events[j] })
+	return events
+}
+
+func MissingEventDefinitions(apis *[]Api, fallbacks map[uint]resolvable.ResponseDefinition) []string {
+	report := []string{}
+	if apis == nil {
+		return report
+	}
+	for _, a := range *apis {
+		missing := lo.Filter(a.EmittedEvents(), func(event uint, _ int) bool {
+			if _, ok := a.Response[event]; ok {
+				return false
+			}
+			_, ok := fallbacks[event]
+			return !ok
+		})
+		if len(missing) > 0 {
+			report = append(report, fmt.Sprintf("api %s (%s %s) can emit events without a definition: %s",
+				a.Name, a.Method, a.Path, strings.Join(lo.Map(missing, func(event uint, _ int) string {
+					return fmt.Sprint(event)
+				}), ", ")))
+		}
+	}
+	return report
+}
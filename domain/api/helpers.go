@@ -3,23 +3,43 @@ package api
 import (
 	"fmt"
 	"ifttt/handler/domain/configuration"
+	requestvalidator "ifttt/handler/domain/request_validator.go"
 	"ifttt/handler/domain/resolvable"
 )
 
 func AttachResponseProfiles(apis *[]Api, profiles *[]configuration.ResponseProfile) error {
-	transformedProfiles := configuration.TransformProfiles(profiles)
+	resolvedProfiles, err := configuration.ResolveProfiles(profiles)
+	if err != nil {
+		return err
+	}
 
 	for idx, a := range *apis {
+		if a.Response == nil {
+			a.Response = map[uint]resolvable.ResponseDefinition{}
+			(*apis)[idx].Response = a.Response
+		}
+
 		for event, profile := range a.Response {
 			if profile.UseProfile != "" {
-				if p, ok := (*transformedProfiles)[profile.UseProfile]; !ok {
+				if p, ok := resolvedProfiles.ByName[profile.UseProfile]; !ok {
 					return fmt.Errorf("profile %s not found", profile.UseProfile)
+				} else if p.Internal {
+					return fmt.Errorf("profile %s is internal and can only be inherited", p.Name)
 				} else {
-					(*apis)[idx].Response[event] = resolvable.ResponseDefinition{
-						UseProfile:     p.Name,
-						Definition:     p.BodyFormat,
-						HTTPStatusCode: p.ResponseHTTPStatus,
-					}
+					profile.UseProfile = p.Name
+					profile.Definition = p.BodyFormat
+					profile.HTTPStatusCode = p.ResponseHTTPStatus
+					a.Response[event] = profile
+				}
+			}
+		}
+
+		for event, p := range resolvedProfiles.ByEvent {
+			if _, ok := a.Response[event]; !ok {
+				a.Response[event] = resolvable.ResponseDefinition{
+					UseProfile:     p.Name,
+					Definition:     p.BodyFormat,
+					HTTPStatusCode: p.ResponseHTTPStatus,
 				}
 			}
 		}
@@ -27,3 +47,21 @@ func AttachResponseProfiles(apis *[]Api, profiles *[]configuration.ResponseProfi
 
 	return nil
 }
+
+func CompileRequestSchemas(apis *[]Api) error {
+	if apis == nil {
+		return nil
+	}
+	for idx := range *apis {
+		a := &(*apis)[idx]
+		if a.RequestSchema == nil {
+			continue
+		}
+		compiled, err := requestvalidator.CompileJSONSchema(a.RequestSchema)
+		if err != nil {
+			return fmt.Errorf("invalid request schema for api %s: %s", a.Name, err)
+		}
+		a.CompiledRequestSchema = compiled
+	}
+	return nil
+}
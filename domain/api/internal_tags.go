@@ -0,0 +1,100 @@
+package api
+
+import (
+	"fmt"
+	"ifttt/handler/domain/configuration"
+	requestvalidator "ifttt/handler/domain/request_validator.go"
+	"ifttt/handler/domain/resolvable"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+func ValidateInternalTags(
+	apis *[]Api, profiles *[]configuration.ResponseProfile, events []configuration.Event, tags *[]configuration.InternalTag,
+) ([]configuration.InternalTagUsage, error) {
+	usages := map[string]*configuration.InternalTagUsage{}
+	for _, name := range configuration.BuiltinInternalTags {
+		usages[name] = &configuration.InternalTagUsage{Name: name, Writers: []string{}, Readers: []string{}}
+	}
+	if tags != nil {
+		for _, t := range *tags {
+			usages[t.Name] = &configuration.InternalTagUsage{Name: t.Name, Writers: []string{}, Readers: []string{}}
+		}
+	}
+
+	unknown := []string{}
+	use := func(path string, where string, write bool) {
+		name := configuration.InternalTagName(path)
+		usage, ok := usages[name]
+		if !ok {
+			unknown = append(unknown, fmt.Sprintf("%s in %s", path, where))
+			return
+		}
+		if write {
+			usage.Writers = append(usage.Writers, where)
+		} else {
+			usage.Readers = append(usage.Readers, where)
+		}
+	}
+
+	if profiles != nil {
+		for _, p := range *profiles {
+			for _, tag := range configuration.CollectInternalTags(p.BodyFormat) {
+				use(tag, fmt.Sprintf("profile %s", p.Name), false)
+			}
+		}
+	}
+
+	for _, e := range events {
+		for _, tag := range configuration.CollectInternalTags(e.Body) {
+			use(tag, fmt.Sprintf("event %d", e.Code), false)
+		}
+	}
+
+	if apis != nil {
+		for _, a := range *apis {
+			for name, parameter := range a.Request {
+				for _, tag := range parameter.InternalTags() {
+					use(tag, fmt.Sprintf("api %s request %s", a.Name, name), true)
+				}
+			}
+			for _, tag := range requestvalidator.JSONSchemaInternalTags(a.RequestSchema) {
+				use(tag, fmt.Sprintf("api %s request schema", a.Name), true)
+			}
+			for event, definition := range a.Response {
+				for _, tag := range responseInternalTags(&definition) {
+					use(tag, fmt.Sprintf("api %s response %d", a.Name, event), false)
+				}
+			}
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown internal tags: %s", strings.Join(unknown, "; "))
+	}
+
+	result := make([]configuration.InternalTagUsage, 0, len(usages))
+	for _, usage := range usages {
+		usage.Writers = lo.Uniq(usage.Writers)
+		usage.Readers = lo.Uniq(usage.Readers)
+		sort.Strings(usage.Writers)
+		sort.Strings(usage.Readers)
+		result = append(result, *usage)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func responseInternalTags(definition *resolvable.ResponseDefinition) []string {
+	tags := configuration.CollectInternalTags(definition.Definition)
+	for _, val := range []any{definition.Body, definition.Headers, definition.Redirect, definition.FileName, definition.HTTPStatusCode} {
+		tags = append(tags, configuration.CollectInternalTags(val)...)
+	}
+	for _, cookie := range definition.Cookies {
+		tags = append(tags, configuration.CollectInternalTags(cookie.Value)...)
+	}
+	return tags
+}
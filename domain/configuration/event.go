@@ -0,0 +1,121 @@
+package configuration
+
+import (
+	"fmt"
+	"ifttt/handler/common"
+	"net/http"
+	"sort"
+)
+
+type Event struct {
+	ID          uint           `json:"id" mapstructure:"id"`
+	Code        uint           `json:"code" mapstructure:"code"`
+	Name        string         `json:"name" mapstructure:"name"`
+	Description string         `json:"description" mapstructure:"description"`
+	HTTPStatus  int            `json:"httpStatus" mapstructure:"httpStatus"`
+	Body        map[string]any `json:"body" mapstructure:"body"`
+}
+
+type EventPersistentRepository interface {
+	GetAllEvents() (*[]Event, error)
+}
+
+func BuiltinEvents() []Event {
+	return []Event{
+		{
+			Code:        common.EventCodes[common.EventSuccess],
+			Name:        "success",
+			Description: "Request completed successfully",
+			HTTPStatus:  http.StatusOK,
+		},
+		{
+			Code:        common.EventCodes[common.EventExhaust],
+			Name:        "exhaust",
+			Description: "Flows completed without sending a response",
+			HTTPStatus:  http.StatusInternalServerError,
+		},
+		{
+			Code:        common.EventCodes[common.EventBadRequest],
+			Name:        "badRequest",
+			Description: "Request body could not be parsed or validated",
+			HTTPStatus:  http.StatusBadRequest,
+			Body: map[string]any{
+				"responseCode":        common.EventCodes[common.EventBadRequest],
+				"responseDescription": "Request body could not be parsed or validated",
+				"errors":              map[string]any{"internalTag": common.InternalTagErrorValidation},
+			},
+		},
+		{
+			Code:        common.EventCodes[common.EventUserError],
+			Name:        "userError",
+			Description: "Request was rejected by a business rule",
+			HTTPStatus:  http.StatusUnprocessableEntity,
+			Body: map[string]any{
+				"responseCode":        common.EventCodes[common.EventUserError],
+				"responseDescription": "Request was rejected by a business rule",
+				"error":               map[string]any{"internalTag": common.InternalTagErrorUser},
+			},
+		},
+		{
+			Code:        common.EventCodes[common.EventNotFound],
+			Name:        "notFound",
+			Description: "No api is configured for the requested path",
+			HTTPStatus:  http.StatusNotFound,
+		},
+		{
+			Code:        common.EventCodes[common.EventSystemMalfunction],
+			Name:        "systemMalfunction",
+			Description: "Request failed with an internal error",
+			HTTPStatus:  http.StatusInternalServerError,
+		},
+	}
+}
+
+func MergeEvents(events *[]Event) ([]Event, error) {
+	merged := map[uint]Event{}
+	for _, event := range BuiltinEvents() {
+		merged[event.Code] = event
+	}
+
+	if events != nil {
+		configured := map[uint]bool{}
+		for _, event := range *events {
+			if configured[event.Code] {
+				return nil, fmt.Errorf("event code %d configured more than once", event.Code)
+			}
+			configured[event.Code] = true
+
+			if event.HTTPStatus != 0 && (event.HTTPStatus < 100 || event.HTTPStatus > 599) {
+				return nil, fmt.Errorf("event %d has invalid http status %d", event.Code, event.HTTPStatus)
+			}
+			if builtin, ok := merged[event.Code]; ok {
+				if event.Name == "" {
+					event.Name = builtin.Name
+				}
+				if event.Description == "" {
+					event.Description = builtin.Description
+				}
+				if event.HTTPStatus == 0 {
+					event.HTTPStatus = builtin.HTTPStatus
+				}
+				if event.Body == nil {
+					event.Body = builtin.Body
+				}
+			}
+			merged[event.Code] = event
+		}
+	}
+
+	result := make([]Event, 0, len(merged))
+	for _, event := range merged {
+		if event.Body == nil {
+			event.Body = map[string]any{
+				"responseCode":        event.Code,
+				"responseDescription": event.Description,
+			}
+		}
+		result = append(result, event)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Code < result[j].Code })
+	return result, nil
+}
@@ -2,6 +2,7 @@ package configuration
 
 import (
 	"context"
+	"fmt"
 	"ifttt/handler/common"
 	"ifttt/handler/domain/request_data"
 	"reflect"
@@ -10,15 +11,90 @@ import (
 	"github.com/mitchellh/mapstructure"
 )
 
-func TransformProfiles(profiles *[]ResponseProfile) *map[string]ResponseProfile {
+func ResolveProfiles(profiles *[]ResponseProfile) (*ResolvedProfiles, error) {
+	resolved := ResolvedProfiles{ByName: map[string]ResponseProfile{}, ByEvent: map[uint]ResponseProfile{}}
 	if profiles == nil {
-		return nil
+		return &resolved, nil
+	}
+
+	byID := make(map[uint]*ResponseProfile, len(*profiles))
+	for idx := range *profiles {
+		p := &(*profiles)[idx]
+		if _, ok := resolved.ByName[p.Name]; ok {
+			return nil, fmt.Errorf("duplicate response profile %s", p.Name)
+		}
+		resolved.ByName[p.Name] = *p
+		byID[p.ID] = p
+	}
+
+	inherited := map[uint]ResponseProfile{}
+	var inherit func(p *ResponseProfile, visiting map[uint]bool) (ResponseProfile, error)
+	inherit = func(p *ResponseProfile, visiting map[uint]bool) (ResponseProfile, error) {
+		if done, ok := inherited[p.ID]; ok {
+			return done, nil
+		}
+		if p.ParentID == nil {
+			inherited[p.ID] = *p
+			return *p, nil
+		}
+		if visiting[p.ID] {
+			return ResponseProfile{}, fmt.Errorf("cyclic inheritance at response profile %s", p.Name)
+		}
+		visiting[p.ID] = true
+
+		parent, ok := byID[*p.ParentID]
+		if !ok {
+			return ResponseProfile{}, fmt.Errorf("parent %d of response profile %s not found", *p.ParentID, p.Name)
+		}
+		parentResolved, err := inherit(parent, visiting)
+		if err != nil {
+			return ResponseProfile{}, err
+		}
+
+		child := *p
+		child.BodyFormat = mergeBodyFormats(parentResolved.BodyFormat, p.BodyFormat)
+		if child.ResponseHTTPStatus == 0 {
+			child.ResponseHTTPStatus = parentResolved.ResponseHTTPStatus
+		}
+		inherited[p.ID] = child
+		return child, nil
+	}
+
+	for idx := range *profiles {
+		p, err := inherit(&(*profiles)[idx], map[uint]bool{})
+		if err != nil {
+			return nil, err
+		}
+		resolved.ByName[p.Name] = p
+
+		for _, event := range p.Events {
+			if p.Internal {
+				return nil, fmt.Errorf("internal response profile %s cannot be mapped to event %d", p.Name, event)
+			}
+			if existing, ok := resolved.ByEvent[event]; ok && existing.Name != p.Name {
+				return nil, fmt.Errorf("event %d is mapped to response profiles %s and %s", event, existing.Name, p.Name)
+			}
+			resolved.ByEvent[event] = p
+		}
 	}
-	transformed := make(map[string]ResponseProfile, len(*profiles))
-	for _, p := range *profiles {
-		transformed[p.Name] = p
+	return &resolved, nil
+}
+
+func mergeBodyFormats(parent map[string]any, child map[string]any) map[string]any {
+	merged := make(map[string]any, len(parent)+len(child))
+	for k, v := range parent {
+		merged[k] = v
 	}
-	return &transformed
+	for k, v := range child {
+		parentMap, parentOk := merged[k].(map[string]any)
+		childMap, childOk := v.(map[string]any)
+		if parentOk && childOk {
+			merged[k] = mergeBodyFormats(parentMap, childMap)
+		} else {
+			merged[k] = v
+		}
+	}
+	return merged
 }
 
 func ScanToInternalTagFunc(ctx context.Context) func(tagName string, value any) error {
@@ -65,6 +141,13 @@ func ScanFromInternalTags(format map[string]any, ctx context.Context) (*map[stri
 	return &unsynced, nil
 }
 
+func ScanFromInternalTag(val any, ctx context.Context) (any, error) {
+	if val == nil {
+		return nil, nil
+	}
+	return scanFromInternalTagMaybe(val, ctx)
+}
+
 func scanFromInternalTagMaybe(val any, ctx context.Context) (any, error) {
 	reflected := reflect.Indirect(reflect.ValueOf(val))
 	indirectValue := reflected.Interface()
@@ -88,6 +171,16 @@ func scanFromInternalTagMaybe(val any, ctx context.Context) (any, error) {
 				return recursedMap, nil
 			}
 		}
+	} else if reflected.Kind() == reflect.Slice && reflected.Type().Elem().Kind() != reflect.Uint8 {
+		scanned := make([]any, reflected.Len())
+		for idx := range scanned {
+			if v, err := ScanFromInternalTag(reflected.Index(idx).Interface(), ctx); err != nil {
+				return nil, err
+			} else {
+				scanned[idx] = v
+			}
+		}
+		return scanned, nil
 	} else {
 		return indirectValue, nil
 	}
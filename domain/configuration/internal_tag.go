@@ -1,5 +1,11 @@
 package configuration
 
+import (
+	"ifttt/handler/common"
+	"reflect"
+	"strings"
+)
+
 type InternalTag struct {
 	ID   uint   `json:"id" mapstructure:"id"`
 	Name string `json:"name" mapstructure:"name"`
@@ -8,3 +14,52 @@ type InternalTag struct {
 type InternalTagInMap struct {
 	InternalTag string `json:"internalTag" mapstructure:"internalTag"`
 }
+
+type InternalTagUsage struct {
+	Name    string   `json:"name" mapstructure:"name"`
+	Writers []string `json:"writers" mapstructure:"writers"`
+	Readers []string `json:"readers" mapstructure:"readers"`
+}
+
+type InternalTagPersistentRepository interface {
+	GetAllInternalTags() (*[]InternalTag, error)
+}
+
+var BuiltinInternalTags = []string{
+	common.InternalTagErrorValidation,
+	common.InternalTagErrorSystem,
+	common.InternalTagErrorUser,
+}
+
+func InternalTagName(path string) string {
+	path = strings.TrimPrefix(strings.TrimSpace(path), ".")
+	if idx := strings.IndexAny(path, ".[|? "); idx >= 0 {
+		path = path[:idx]
+	}
+	return strings.Trim(path, `"`)
+}
+
+func CollectInternalTags(val any) []string {
+	tags := []string{}
+	rv := reflect.Indirect(reflect.ValueOf(val))
+	if !rv.IsValid() {
+		return tags
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		for _, key := range rv.MapKeys() {
+			value := rv.MapIndex(key).Interface()
+			if tag, ok := value.(string); ok && key.Kind() == reflect.String && key.String() == "internalTag" {
+				tags = append(tags, tag)
+			} else {
+				tags = append(tags, CollectInternalTags(value)...)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for idx := 0; idx < rv.Len(); idx++ {
+			tags = append(tags, CollectInternalTags(rv.Index(idx).Interface())...)
+		}
+	}
+	return tags
+}
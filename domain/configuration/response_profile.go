@@ -5,6 +5,14 @@ type ResponseProfile struct {
 	Name               string         `json:"name" mapstructure:"name"`
 	BodyFormat         map[string]any `json:"bodyFormat" mapstructure:"bodyFormat"`
 	ResponseHTTPStatus int            `json:"responseHTTPStatus" mapstructure:"responseHTTPStatus"`
+	Internal           bool           `json:"internal" mapstructure:"internal"`
+	ParentID           *uint          `json:"parentId" mapstructure:"parentId"`
+	Events             []uint         `json:"events" mapstructure:"events"`
+}
+
+type ResolvedProfiles struct {
+	ByName  map[string]ResponseProfile
+	ByEvent map[uint]ResponseProfile
 }
 
 type ResponseProfilePersistentRepository interface {
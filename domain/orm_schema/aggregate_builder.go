@@ -0,0 +1,281 @@
+package orm_schema
+
+import (
+	"fmt"
+	"ifttt/handler/common"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+type Aggregate struct {
+	Function string `json:"function" mapstructure:"function"`
+	Field    string `json:"field" mapstructure:"field"`
+	As       string `json:"as" mapstructure:"as"`
+	Distinct bool   `json:"distinct" mapstructure:"distinct"`
+}
+
+type AggregateColumn struct {
+	Label      string
+	Function   string
+	Projection Projection
+}
+
+type aggregateField struct {
+	qualified  string
+	alias      string
+	projection Projection
+}
+
+var aggregateFunctions = map[string]bool{
+	common.AggregateCount: true,
+	common.AggregateSum:   true,
+	common.AggregateAvg:   true,
+	common.AggregateMin:   true,
+	common.AggregateMax:   true,
+}
+
+func BuildAggregate(
+	dialect string,
+	mainModel *Model,
+	populate []Populate,
+	where *Where,
+	groupBy []string,
+	aggregates []Aggregate,
+	withDeleted bool,
+	models map[string]*Model,
+) (*BuiltQuery, []AggregateColumn, error) {
+	if len(aggregates) == 0 {
+		return nil, nil, fmt.Errorf("at least one aggregate is required")
+	}
+
+	b := selectBuilder{dialect: dialect, withDeleted: withDeleted, models: models}
+	if err := b.addJoins(mainModel.Name, mainModel, populate); err != nil {
+		return nil, nil, err
+	}
+	joinPaths := map[string][]string{mainModel.Name: {mainModel.Name}}
+	fanOut := []string{}
+	if err := b.collectFanOut(mainModel.Name, mainModel, populate, joinPaths, &fanOut); err != nil {
+		return nil, nil, err
+	}
+	inflatedBy := func(alias string) string {
+		for _, f := range fanOut {
+			if !lo.Contains(joinPaths[alias], f) {
+				return f
+			}
+		}
+		return ""
+	}
+
+	var (
+		selected     []string
+		groupColumns []string
+		columns      []AggregateColumn
+		labels       = map[string]bool{}
+	)
+	addLabel := func(label string) error {
+		if labels[label] {
+			return fmt.Errorf("duplicate aggregate label %s", label)
+		}
+		labels[label] = true
+		return nil
+	}
+
+	for _, path := range groupBy {
+		field, err := b.resolveField(path, mainModel, populate)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := addLabel(path); err != nil {
+			return nil, nil, err
+		}
+		selected = append(selected, fmt.Sprintf("%s AS %s", field.qualified, b.quote(path)))
+		groupColumns = append(groupColumns, field.qualified)
+		columns = append(columns, AggregateColumn{Label: path, Projection: field.projection})
+	}
+
+	for _, a := range aggregates {
+		function := strings.ToLower(a.Function)
+		if !aggregateFunctions[function] {
+			return nil, nil, fmt.Errorf("aggregate function %s not supported", a.Function)
+		}
+
+		label := a.As
+		if label == "" {
+			label = strings.Trim(strings.ReplaceAll(function+"_"+a.Field, ".", "_"), "_")
+		}
+		if err := addLabel(label); err != nil {
+			return nil, nil, err
+		}
+
+		var (
+			argument   string
+			projection Projection
+		)
+		if a.Field == "" {
+			if function != common.AggregateCount || a.Distinct {
+				return nil, nil, fmt.Errorf("field is required for aggregate %s", label)
+			}
+			if len(fanOut) > 0 {
+				return nil, nil, fmt.Errorf(
+					"aggregate %s counts rows multiplied by to-many join %s, count a field instead", label, fanOut[0])
+			}
+			argument = "*"
+		} else {
+			field, err := b.resolveField(a.Field, mainModel, populate)
+			if err != nil {
+				return nil, nil, err
+			}
+			if f := inflatedBy(field.alias); f != "" && function != common.AggregateMin &&
+				function != common.AggregateMax && !(function == common.AggregateCount && a.Distinct) {
+				return nil, nil, fmt.Errorf(
+					"aggregate %s over %s would be multiplied by to-many join %s", label, a.Field, f)
+			}
+			argument = field.qualified
+			if a.Distinct {
+				argument = "DISTINCT " + argument
+			}
+			projection = field.projection
+		}
+
+		selected = append(selected, fmt.Sprintf("%s(%s) AS %s", strings.ToUpper(function), argument, b.quote(label)))
+		columns = append(columns, AggregateColumn{Label: label, Function: function, Projection: projection})
+	}
+
+	var (
+		query      strings.Builder
+		parameters []any
+	)
+	query.WriteString("SELECT ")
+	query.WriteString(strings.Join(selected, ", "))
+	query.WriteString(fmt.Sprintf(" FROM %s AS %s", b.quote(mainModel.Table), b.quote(mainModel.Name)))
+	query.WriteString(b.joins.String())
+	parameters = append(parameters, b.joinParams...)
+	clause, whereParams, err := b.compileWhere(where,
+		whereScope{alias: mainModel.Name, model: mainModel, populate: populate})
+	if err != nil {
+		return nil, nil, err
+	}
+	conditions := []string{}
+	if clause != "" {
+		conditions = append(conditions, clause)
+		parameters = append(parameters, whereParams...)
+	}
+	if clause := b.softDeleteClause(mainModel.Name, mainModel); clause != "" {
+		conditions = append(conditions, clause)
+	}
+	if len(conditions) > 0 {
+		query.WriteString(" WHERE " + strings.Join(conditions, " AND "))
+	}
+	if len(groupColumns) > 0 {
+		query.WriteString(" GROUP BY " + strings.Join(groupColumns, ", "))
+		query.WriteString(" ORDER BY " + strings.Join(groupColumns, ", "))
+	}
+
+	return &BuiltQuery{QueryString: query.String(), Parameters: parameters}, columns, nil
+}
+
+func (b *selectBuilder) collectFanOut(
+	alias string, model *Model, populate []Populate, joinPaths map[string][]string, fanOut *[]string,
+) error {
+	for _, p := range populate {
+		childModel, ok := b.models[p.Model]
+		if !ok || childModel == nil {
+			return fmt.Errorf("model %s not found", p.Model)
+		}
+		association, err := model.ResolveAssociation(childModel, p.Association)
+		if err != nil {
+			return err
+		}
+
+		childAlias := fmt.Sprintf("%s_%s", alias, p.As)
+		joinPaths[childAlias] = append(append([]string{}, joinPaths[alias]...), childAlias)
+		if association.Type == common.AssociationsHasMany || association.Type == common.AssociationsBelongsToMany {
+			*fanOut = append(*fanOut, childAlias)
+		}
+		if err := b.collectFanOut(childAlias, childModel, p.Populate, joinPaths, fanOut); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *selectBuilder) resolveField(path string, mainModel *Model, populate []Populate) (*aggregateField, error) {
+	return b.resolveFieldIn(path, mainModel.Name, mainModel, nil, populate)
+}
+
+func (b *selectBuilder) resolveFieldIn(
+	path string, alias string, model *Model, project []Projection, populate []Populate,
+) (*aggregateField, error) {
+	segments := strings.Split(path, ".")
+
+	for _, segment := range segments[:len(segments)-1] {
+		var found *Populate
+		for idx := range populate {
+			if populate[idx].As == segment {
+				found = &populate[idx]
+				break
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("populate %s not found for field %s", segment, path)
+		}
+
+		childModel, ok := b.models[found.Model]
+		if !ok || childModel == nil {
+			return nil, fmt.Errorf("model %s not found", found.Model)
+		}
+		alias = fmt.Sprintf("%s_%s", alias, found.As)
+		model, project, populate = childModel, found.Project, found.Populate
+	}
+
+	field := segments[len(segments)-1]
+	column, ok := projectedColumn(field, model, project)
+	if !ok {
+		return nil, fmt.Errorf("field %s not found", path)
+	}
+
+	projection := Projection{Column: column, As: field, ModelType: common.DatabaseTypeString}
+	for _, projections := range [][]Projection{project, model.Projections} {
+		if p, ok := findProjection(projections, field); ok {
+			projection = p
+			break
+		}
+	}
+
+	return &aggregateField{
+		qualified:  fmt.Sprintf("%s.%s", b.quote(alias), b.quote(column)),
+		alias:      alias,
+		projection: projection,
+	}, nil
+}
+
+func findProjection(projections []Projection, as string) (Projection, bool) {
+	for _, p := range projections {
+		if p.As == as {
+			return p, true
+		}
+	}
+	return Projection{}, false
+}
+
+func (c *AggregateColumn) Sanitize(val any) (any, error) {
+	if val == nil && c.Function != common.AggregateCount {
+		return nil, nil
+	}
+
+	projection := c.Projection
+	projection.NotNull = false
+
+	switch c.Function {
+	case common.AggregateCount:
+		projection = Projection{ModelType: common.DatabaseTypeNumber, NotNull: true}
+	case common.AggregateSum, common.AggregateAvg:
+		projection = Projection{ModelType: common.DatabaseTypeNumber}
+	}
+	if projection.ModelType == common.DatabaseTypeNumber {
+		projection.SchemaType = common.DatabaseTypeString
+	}
+
+	return projection.SanitizeValue(val, true)
+}
@@ -90,3 +90,59 @@ func (p *Projection) SanitizeValue(val any, exists bool) (any, error) {
 		return val, nil
 	}
 }
+
+func (p *Projection) ToSchemaValue(val any) (any, error) {
+	if val == nil {
+		if p.NotNull {
+			return nil, fmt.Errorf("value cannot be null")
+		}
+		return nil, nil
+	}
+
+	switch p.ModelType {
+	case common.DatabaseTypeString:
+		if _, ok := val.(string); !ok {
+			return nil, fmt.Errorf("expected %s, got %T", p.ModelType, val)
+		}
+	case common.DatabaseTypeNumber:
+		if !common.IsNumber(val) {
+			return nil, fmt.Errorf("expected %s, got %T", p.ModelType, val)
+		}
+	case common.DatabaseTypeBoolean:
+		if _, ok := val.(bool); !ok {
+			return nil, fmt.Errorf("expected %s, got %T", p.ModelType, val)
+		}
+	default:
+		return val, nil
+	}
+
+	if p.ModelType == p.SchemaType || p.SchemaType == "" {
+		return val, nil
+	}
+
+	switch p.SchemaType {
+	case common.DatabaseTypeString:
+		return fmt.Sprint(val), nil
+	case common.DatabaseTypeNumber:
+		if b, ok := val.(bool); ok {
+			if b {
+				return 1, nil
+			}
+			return 0, nil
+		} else if parsed, err := strconv.ParseFloat(fmt.Sprint(val), 64); err != nil {
+			return nil, fmt.Errorf("cast to %s failed: %s", p.SchemaType, err)
+		} else {
+			return parsed, nil
+		}
+	case common.DatabaseTypeBoolean:
+		if parsed, err := strconv.ParseFloat(fmt.Sprint(val), 64); err == nil {
+			return parsed != 0, nil
+		} else if parsed, err := strconv.ParseBool(fmt.Sprint(val)); err != nil {
+			return nil, fmt.Errorf("cast to %s failed: %s", p.SchemaType, err)
+		} else {
+			return parsed, nil
+		}
+	default:
+		return val, nil
+	}
+}
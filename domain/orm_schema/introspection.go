@@ -0,0 +1,224 @@
+package orm_schema
+
+import (
+	"fmt"
+	"ifttt/handler/common"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	numericTypes = map[string]bool{
+		"int": true, "integer": true, "tinyint": true, "smallint": true, "mediumint": true, "bigint": true,
+		"int2": true, "int4": true, "int8": true, "big int": true,
+		"serial": true, "smallserial": true, "bigserial": true, "serial2": true, "serial4": true, "serial8": true,
+		"decimal": true, "dec": true, "numeric": true, "fixed": true,
+		"float": true, "float4": true, "float8": true, "double": true, "double precision": true, "real": true,
+	}
+	typeModifiers = map[string]bool{"unsigned": true, "signed": true, "zerofill": true}
+	typeLength    = regexp.MustCompile(`\([^)]*\)`)
+)
+
+type DatabaseSchema struct {
+	Columns     []SchemaColumn     `json:"columns" mapstructure:"columns"`
+	ForeignKeys []SchemaForeignKey `json:"foreignKeys" mapstructure:"foreignKeys"`
+}
+
+type SchemaColumn struct {
+	Table      string `json:"table" mapstructure:"table"`
+	Name       string `json:"name" mapstructure:"name"`
+	DataType   string `json:"dataType" mapstructure:"dataType"`
+	Nullable   bool   `json:"nullable" mapstructure:"nullable"`
+	PrimaryKey bool   `json:"primaryKey" mapstructure:"primaryKey"`
+	Unique     bool   `json:"unique" mapstructure:"unique"`
+}
+
+type SchemaForeignKey struct {
+	Table            string `json:"table" mapstructure:"table"`
+	Column           string `json:"column" mapstructure:"column"`
+	ReferencesTable  string `json:"referencesTable" mapstructure:"referencesTable"`
+	ReferencesColumn string `json:"referencesColumn" mapstructure:"referencesColumn"`
+}
+
+type ModelBundle struct {
+	Models       []Model            `json:"models" mapstructure:"models"`
+	Associations []ModelAssociation `json:"associations" mapstructure:"associations"`
+	Skipped      []string           `json:"skipped,omitempty" mapstructure:"skipped"`
+}
+
+func ProposeModels(schema *DatabaseSchema, tables []string) (*ModelBundle, error) {
+	include := func(table string) bool {
+		if len(tables) == 0 {
+			return true
+		}
+		for _, t := range tables {
+			if strings.EqualFold(t, table) {
+				return true
+			}
+		}
+		return false
+	}
+
+	columnsByTable := map[string][]SchemaColumn{}
+	tableNames := []string{}
+	for _, c := range schema.Columns {
+		if !include(c.Table) {
+			continue
+		}
+		if _, ok := columnsByTable[c.Table]; !ok {
+			tableNames = append(tableNames, c.Table)
+		}
+		columnsByTable[c.Table] = append(columnsByTable[c.Table], c)
+	}
+	sort.Strings(tableNames)
+
+	bundle := ModelBundle{}
+	compositeTables := map[string]bool{}
+	uniqueColumns := map[string]bool{}
+	for _, table := range tableNames {
+		model := Model{Name: table, Table: table}
+		primaryKeys := []string{}
+		for _, c := range columnsByTable[table] {
+			if c.PrimaryKey {
+				primaryKeys = append(primaryKeys, c.Name)
+			}
+			if c.Unique || c.PrimaryKey {
+				uniqueColumns[table+"."+c.Name] = true
+			}
+			switch strings.ToLower(c.Name) {
+			case common.OrmColumnDeletedAt:
+				model.SoftDeleteColumn = c.Name
+			case common.OrmColumnCreatedAt:
+				model.CreatedAtColumn = c.Name
+			case common.OrmColumnUpdatedAt:
+				model.UpdatedAtColumn = c.Name
+			}
+			schemaType := SchemaTypeFromDataType(c.DataType)
+			model.Projections = append(model.Projections, Projection{
+				Column:     c.Name,
+				As:         c.Name,
+				SchemaType: schemaType,
+				ModelType:  schemaType,
+				NotNull:    !c.Nullable,
+			})
+		}
+
+		if len(primaryKeys) != 1 {
+			compositeTables[table] = true
+			continue
+		}
+		model.PrimaryKey = primaryKeys[0]
+		bundle.Models = append(bundle.Models, model)
+	}
+
+	models := make(map[string]*Model, len(bundle.Models))
+	for idx := range bundle.Models {
+		models[bundle.Models[idx].Table] = &bundle.Models[idx]
+	}
+
+	joinTableKeys := map[string][]SchemaForeignKey{}
+	for _, fk := range schema.ForeignKeys {
+		if compositeTables[fk.Table] {
+			joinTableKeys[fk.Table] = append(joinTableKeys[fk.Table], fk)
+			continue
+		}
+		owning, ok := models[fk.Table]
+		if !ok {
+			continue
+		}
+		referenced, ok := models[fk.ReferencesTable]
+		if !ok {
+			bundle.Skipped = append(bundle.Skipped,
+				fmt.Sprintf("%s.%s: referenced table %s not modelled", fk.Table, fk.Column, fk.ReferencesTable))
+			continue
+		}
+
+		inverseType := common.AssociationsHasMany
+		if uniqueColumns[fk.Table+"."+fk.Column] {
+			inverseType = common.AssociationsHasOne
+		}
+
+		bundle.Associations = append(bundle.Associations,
+			ModelAssociation{
+				Name:            fmt.Sprintf("%s_%s_%s", owning.Name, fk.Column, referenced.Name),
+				Type:            common.AssociationsBelongsTo,
+				TableName:       fk.Table,
+				ColumnName:      fk.Column,
+				ReferencesTable: fk.ReferencesTable,
+				ReferencesField: fk.ReferencesColumn,
+				OwningModel:     Model{Name: owning.Name, Table: owning.Table},
+				ReferencesModel: Model{Name: referenced.Name, Table: referenced.Table},
+			},
+			ModelAssociation{
+				Name:            fmt.Sprintf("%s_%s_%s", referenced.Name, fk.Column, owning.Name),
+				Type:            inverseType,
+				TableName:       fk.ReferencesTable,
+				ColumnName:      fk.ReferencesColumn,
+				ReferencesTable: fk.Table,
+				ReferencesField: fk.Column,
+				OwningModel:     Model{Name: referenced.Name, Table: referenced.Table},
+				ReferencesModel: Model{Name: owning.Name, Table: owning.Table},
+			},
+		)
+	}
+
+	for _, table := range tableNames {
+		if !compositeTables[table] {
+			continue
+		}
+		keys := joinTableKeys[table]
+		if len(keys) != 2 || models[keys[0].ReferencesTable] == nil || models[keys[1].ReferencesTable] == nil {
+			bundle.Skipped = append(bundle.Skipped,
+				fmt.Sprintf("%s: expected a single column primary key or a join table between two models", table))
+			continue
+		}
+
+		for _, pair := range [][2]SchemaForeignKey{{keys[0], keys[1]}, {keys[1], keys[0]}} {
+			source, target := pair[0], pair[1]
+			owning, referenced := models[source.ReferencesTable], models[target.ReferencesTable]
+			name := fmt.Sprintf("%s_%s_%s", owning.Name, table, referenced.Name)
+			if owning == referenced {
+				name = fmt.Sprintf("%s_%s_%s", owning.Name, table, target.Column)
+			}
+			bundle.Associations = append(bundle.Associations, ModelAssociation{
+				Name:                 name,
+				Type:                 common.AssociationsBelongsToMany,
+				TableName:            owning.Table,
+				ColumnName:           source.ReferencesColumn,
+				ReferencesTable:      referenced.Table,
+				ReferencesField:      target.ReferencesColumn,
+				JoinTable:            table,
+				JoinTableSourceField: source.Column,
+				JoinTableTargetField: target.Column,
+				OwningModel:          Model{Name: owning.Name, Table: owning.Table},
+				ReferencesModel:      Model{Name: referenced.Name, Table: referenced.Table},
+			})
+		}
+	}
+
+	return &bundle, nil
+}
+
+func SchemaTypeFromDataType(dataType string) string {
+	dataType = strings.ToLower(strings.TrimSpace(dataType))
+	if strings.HasSuffix(dataType, "]") {
+		return common.DatabaseTypeString
+	}
+	words := []string{}
+	for _, word := range strings.Fields(typeLength.ReplaceAllString(dataType, " ")) {
+		if !typeModifiers[word] {
+			words = append(words, word)
+		}
+	}
+	baseType := strings.Join(words, " ")
+
+	switch {
+	case baseType == "bool" || baseType == "boolean",
+		dataType == "bit" || dataType == "bit(1)" || dataType == "tinyint(1)":
+		return common.DatabaseTypeBoolean
+	case numericTypes[baseType]:
+		return common.DatabaseTypeNumber
+	}
+	return common.DatabaseTypeString
+}
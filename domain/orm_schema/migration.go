@@ -0,0 +1,427 @@
+package orm_schema
+
+import (
+	"fmt"
+	"ifttt/handler/common"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+type MigrationPlan struct {
+	Statements []MigrationStatement `json:"statements" mapstructure:"statements"`
+	Warnings   []string             `json:"warnings,omitempty" mapstructure:"warnings"`
+}
+
+type MigrationStatement struct {
+	Table       string `json:"table" mapstructure:"table"`
+	Description string `json:"description" mapstructure:"description"`
+	Statement   string `json:"statement" mapstructure:"statement"`
+	Alter       bool   `json:"alter,omitempty" mapstructure:"alter"`
+}
+
+type migrationColumn struct {
+	name       string
+	schemaType string
+	notNull    bool
+	timestamp  bool
+	key        bool
+}
+
+type migrationTable struct {
+	name        string
+	columns     []migrationColumn
+	primaryKeys []string
+	autoKey     bool
+}
+
+type migrationForeignKey struct {
+	table            string
+	column           string
+	referencesTable  string
+	referencesColumn string
+}
+
+type migrationPlanner struct {
+	dialect  string
+	existing map[string]map[string]SchemaColumn
+	tables   map[string]*migrationTable
+	plan     MigrationPlan
+}
+
+func PlanMigration(
+	dialect string, models []Model, associations []ModelAssociation, schema *DatabaseSchema,
+) (*MigrationPlan, error) {
+	p := migrationPlanner{
+		dialect:  dialect,
+		existing: map[string]map[string]SchemaColumn{},
+		tables:   map[string]*migrationTable{},
+	}
+	for _, c := range schema.Columns {
+		if p.existing[c.Table] == nil {
+			p.existing[c.Table] = map[string]SchemaColumn{}
+		}
+		p.existing[c.Table][c.Name] = c
+	}
+
+	for idx := range models {
+		if err := p.addModel(&models[idx]); err != nil {
+			return nil, err
+		}
+	}
+
+	foreignKeys, err := p.addAssociations(associations)
+	if err != nil {
+		return nil, err
+	}
+
+	existingKeys := map[migrationForeignKey]bool{}
+	for _, fk := range schema.ForeignKeys {
+		existingKeys[migrationForeignKey{fk.Table, fk.Column, fk.ReferencesTable, fk.ReferencesColumn}] = true
+	}
+	inlineKeys := map[string][]migrationForeignKey{}
+	var alterKeys []migrationForeignKey
+	for _, fk := range foreignKeys {
+		if existingKeys[fk] {
+			continue
+		}
+		if _, exists := p.existing[fk.table]; !exists && p.dialect == common.DialectSQLite {
+			inlineKeys[fk.table] = append(inlineKeys[fk.table], fk)
+		} else {
+			alterKeys = append(alterKeys, fk)
+		}
+	}
+
+	names := make([]string, 0, len(p.tables))
+	for name := range p.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, exists := p.existing[name]; exists {
+			p.alterTable(p.tables[name])
+		} else {
+			p.createTable(p.tables[name], inlineKeys[name])
+		}
+	}
+	for _, fk := range alterKeys {
+		p.addForeignKey(fk)
+	}
+	return &p.plan, nil
+}
+
+func (p *migrationPlanner) quote(identifier string) string {
+	return common.SqlQuoteIdentifier(p.dialect, identifier)
+}
+
+func (p *migrationPlanner) table(name string) *migrationTable {
+	if t, ok := p.tables[name]; ok {
+		return t
+	}
+	t := &migrationTable{name: name}
+	p.tables[name] = t
+	return t
+}
+
+func (t *migrationTable) column(name string) *migrationColumn {
+	for idx := range t.columns {
+		if t.columns[idx].name == name {
+			return &t.columns[idx]
+		}
+	}
+	return nil
+}
+
+func (t *migrationTable) addColumn(c migrationColumn) {
+	if existing := t.column(c.name); existing != nil {
+		existing.notNull = existing.notNull || c.notNull
+		existing.timestamp = existing.timestamp || c.timestamp
+		existing.key = existing.key || c.key
+		return
+	}
+	t.columns = append(t.columns, c)
+}
+
+func (p *migrationPlanner) addModel(m *Model) error {
+	if m.Table == "" {
+		return fmt.Errorf("table not found in model %s", m.Name)
+	} else if m.PrimaryKey == "" {
+		return fmt.Errorf("primary key not found in model %s", m.Name)
+	}
+
+	t := p.table(m.Table)
+	keyType := common.DatabaseTypeNumber
+	for _, projection := range m.Projections {
+		if projection.Column == m.PrimaryKey && projection.SchemaType != "" {
+			keyType = projection.SchemaType
+		}
+	}
+	t.addColumn(migrationColumn{name: m.PrimaryKey, schemaType: keyType, notNull: true, key: true})
+	t.primaryKeys = []string{m.PrimaryKey}
+	t.autoKey = keyType == common.DatabaseTypeNumber
+
+	for _, projection := range m.Projections {
+		schemaType := projection.SchemaType
+		if schemaType == "" {
+			schemaType = common.DatabaseTypeString
+		}
+		t.addColumn(migrationColumn{
+			name:       projection.Column,
+			schemaType: schemaType,
+			notNull:    projection.NotNull,
+			timestamp:  m.isTimestamp(projection.Column),
+		})
+	}
+	for _, column := range []string{m.CreatedAtColumn, m.UpdatedAtColumn, m.SoftDeleteColumn} {
+		if column != "" {
+			t.addColumn(migrationColumn{name: column, timestamp: true})
+		}
+	}
+	return nil
+}
+
+func (p *migrationPlanner) addAssociations(associations []ModelAssociation) ([]migrationForeignKey, error) {
+	seen := map[migrationForeignKey]bool{}
+	var foreignKeys []migrationForeignKey
+	add := func(fk migrationForeignKey) {
+		if seen[fk] || !p.known(fk.table) || !p.known(fk.referencesTable) {
+			return
+		}
+		seen[fk] = true
+		foreignKeys = append(foreignKeys, fk)
+		if t, ok := p.tables[fk.table]; ok {
+			if c := t.column(fk.column); c != nil {
+				c.key = true
+			}
+		}
+	}
+
+	for _, a := range associations {
+		switch a.Type {
+		case common.AssociationsBelongsTo:
+			add(migrationForeignKey{a.TableName, a.ColumnName, a.ReferencesTable, a.ReferencesField})
+		case common.AssociationsHasOne, common.AssociationsHasMany:
+			add(migrationForeignKey{a.ReferencesTable, a.ReferencesField, a.TableName, a.ColumnName})
+		case common.AssociationsBelongsToMany:
+			if a.JoinTable == "" {
+				return nil, fmt.Errorf("join table not found in association %s", a.Name)
+			}
+			if _, exists := p.existing[a.JoinTable]; !exists {
+				t := p.table(a.JoinTable)
+				t.addColumn(migrationColumn{
+					name: a.JoinTableSourceField, schemaType: p.referencedType(a.TableName, a.ColumnName), notNull: true, key: true,
+				})
+				t.addColumn(migrationColumn{
+					name: a.JoinTableTargetField, schemaType: p.referencedType(a.ReferencesTable, a.ReferencesField), notNull: true, key: true,
+				})
+				if len(t.primaryKeys) == 0 {
+					t.primaryKeys = []string{a.JoinTableSourceField, a.JoinTableTargetField}
+				}
+			}
+			add(migrationForeignKey{a.JoinTable, a.JoinTableSourceField, a.TableName, a.ColumnName})
+			add(migrationForeignKey{a.JoinTable, a.JoinTableTargetField, a.ReferencesTable, a.ReferencesField})
+		default:
+			return nil, fmt.Errorf("association type %s not supported in association %s", a.Type, a.Name)
+		}
+	}
+	return foreignKeys, nil
+}
+
+func (p *migrationPlanner) known(table string) bool {
+	if _, ok := p.tables[table]; ok {
+		return true
+	}
+	_, ok := p.existing[table]
+	return ok
+}
+
+func (p *migrationPlanner) referencedType(table string, column string) string {
+	if t, ok := p.tables[table]; ok {
+		if c := t.column(column); c != nil {
+			return c.schemaType
+		}
+	}
+	if c, ok := p.existing[table][column]; ok {
+		return SchemaTypeFromDataType(c.DataType)
+	}
+	return common.DatabaseTypeNumber
+}
+
+func (p *migrationPlanner) createTable(t *migrationTable, foreignKeys []migrationForeignKey) {
+	autoKey := t.autoKey && len(t.primaryKeys) == 1
+	definitions := []string{}
+	for _, c := range t.columns {
+		if autoKey && c.name == t.primaryKeys[0] {
+			definitions = append(definitions, p.quote(c.name)+" "+p.autoKeyType())
+			continue
+		}
+		definitions = append(definitions, p.columnDefinition(c, true))
+	}
+
+	if !autoKey || p.dialect != common.DialectSQLite {
+		keys := make([]string, len(t.primaryKeys))
+		for idx, key := range t.primaryKeys {
+			keys[idx] = p.quote(key)
+		}
+		definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(keys, ", ")))
+	}
+	for _, fk := range foreignKeys {
+		definitions = append(definitions, fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)",
+			p.quote(fk.column), p.quote(fk.referencesTable), p.quote(fk.referencesColumn)))
+	}
+
+	p.plan.Statements = append(p.plan.Statements, MigrationStatement{
+		Table:       t.name,
+		Description: fmt.Sprintf("create table %s", t.name),
+		Statement:   fmt.Sprintf("CREATE TABLE %s (\n\t%s\n)", p.quote(t.name), strings.Join(definitions, ",\n\t")),
+	})
+}
+
+func (p *migrationPlanner) alterTable(t *migrationTable) {
+	existing := p.existing[t.name]
+	for _, c := range t.columns {
+		current, ok := existing[c.name]
+		if !ok {
+			description := fmt.Sprintf("add column %s.%s", t.name, c.name)
+			if c.notNull {
+				description += " as nullable, backfill before enforcing not null"
+			}
+			p.plan.Statements = append(p.plan.Statements, MigrationStatement{
+				Table:       t.name,
+				Description: description,
+				Statement: fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s",
+					p.quote(t.name), p.columnDefinition(c, false)),
+			})
+			continue
+		}
+
+		typeDrift := false
+		if !c.timestamp {
+			if currentType := SchemaTypeFromDataType(current.DataType); currentType != c.schemaType {
+				typeDrift = true
+				p.plan.Warnings = append(p.plan.Warnings, fmt.Sprintf("column %s.%s is %s in the data store, model expects %s",
+					t.name, c.name, currentType, c.schemaType))
+			}
+		}
+		nullDrift := c.notNull && current.Nullable
+		if nullDrift {
+			p.plan.Warnings = append(p.plan.Warnings, fmt.Sprintf("column %s.%s is nullable in the data store, model expects not null",
+				t.name, c.name))
+		}
+		if typeDrift || nullDrift {
+			p.alterColumn(t, c, current, typeDrift, nullDrift)
+		}
+	}
+}
+
+// alterColumn plans statements that change an existing column to match the
+// model, these can fail on existing data so they are only applied on request
+func (p *migrationPlanner) alterColumn(
+	t *migrationTable, c migrationColumn, current SchemaColumn, typeDrift bool, nullDrift bool,
+) {
+	if p.dialect == common.DialectSQLite {
+		p.plan.Warnings = append(p.plan.Warnings, fmt.Sprintf("column %s.%s cannot be altered in an existing sqlite table",
+			t.name, c.name))
+		return
+	} else if lo.Contains(t.primaryKeys, c.name) {
+		p.plan.Warnings = append(p.plan.Warnings, fmt.Sprintf("primary key %s.%s must be altered manually",
+			t.name, c.name))
+		return
+	}
+
+	alter := func(description string, statement string) {
+		p.plan.Statements = append(p.plan.Statements, MigrationStatement{
+			Table:       t.name,
+			Description: description,
+			Statement:   fmt.Sprintf("ALTER TABLE %s %s", p.quote(t.name), statement),
+			Alter:       true,
+		})
+	}
+	if p.dialect == common.DialectMySQL {
+		c.notNull = c.notNull || !current.Nullable
+		alter(fmt.Sprintf("modify column %s.%s", t.name, c.name),
+			"MODIFY COLUMN "+p.columnDefinition(c, true))
+		return
+	}
+	if typeDrift {
+		columnType := p.columnType(c)
+		alter(fmt.Sprintf("change type of column %s.%s to %s", t.name, c.name, columnType),
+			fmt.Sprintf("ALTER COLUMN %s TYPE %s USING %s::%s", p.quote(c.name), columnType, p.quote(c.name), columnType))
+	}
+	if nullDrift {
+		alter(fmt.Sprintf("set column %s.%s not null", t.name, c.name),
+			fmt.Sprintf("ALTER COLUMN %s SET NOT NULL", p.quote(c.name)))
+	}
+}
+
+func (p *migrationPlanner) addForeignKey(fk migrationForeignKey) {
+	if p.dialect == common.DialectSQLite {
+		p.plan.Warnings = append(p.plan.Warnings, fmt.Sprintf("foreign key %s.%s -> %s.%s cannot be added to an existing sqlite table",
+			fk.table, fk.column, fk.referencesTable, fk.referencesColumn))
+		return
+	}
+
+	name := fmt.Sprintf("fk_%s_%s", fk.table, fk.column)
+	p.plan.Statements = append(p.plan.Statements, MigrationStatement{
+		Table:       fk.table,
+		Description: fmt.Sprintf("add foreign key %s.%s -> %s.%s", fk.table, fk.column, fk.referencesTable, fk.referencesColumn),
+		Statement: fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+			p.quote(fk.table), p.quote(name), p.quote(fk.column), p.quote(fk.referencesTable), p.quote(fk.referencesColumn)),
+	})
+}
+
+func (p *migrationPlanner) columnDefinition(c migrationColumn, enforceNotNull bool) string {
+	definition := p.quote(c.name) + " " + p.columnType(c)
+	if c.notNull && enforceNotNull {
+		definition += " NOT NULL"
+	}
+	return definition
+}
+
+func (p *migrationPlanner) autoKeyType() string {
+	switch p.dialect {
+	case common.DialectMySQL:
+		return "BIGINT NOT NULL AUTO_INCREMENT"
+	case common.DialectPostgres:
+		return "BIGSERIAL NOT NULL"
+	default:
+		return "INTEGER PRIMARY KEY AUTOINCREMENT"
+	}
+}
+
+func (p *migrationPlanner) columnType(c migrationColumn) string {
+	if c.timestamp {
+		if p.dialect == common.DialectMySQL {
+			return "DATETIME"
+		}
+		return "TIMESTAMP"
+	}
+
+	switch c.schemaType {
+	case common.DatabaseTypeNumber:
+		switch {
+		case p.dialect == common.DialectSQLite && c.key:
+			return "INTEGER"
+		case p.dialect == common.DialectSQLite:
+			return "REAL"
+		case c.key:
+			return "BIGINT"
+		case p.dialect == common.DialectPostgres:
+			return "DOUBLE PRECISION"
+		default:
+			return "DOUBLE"
+		}
+	case common.DatabaseTypeBoolean:
+		if p.dialect == common.DialectMySQL {
+			return "TINYINT(1)"
+		}
+		return "BOOLEAN"
+	default:
+		if p.dialect == common.DialectMySQL {
+			return "VARCHAR(255)"
+		}
+		return "TEXT"
+	}
+}
@@ -5,6 +5,9 @@ type Model struct {
 	Table                  string             `mapstructure:"table" json:"table"`
 	Projections            []Projection       `mapstructure:"projections" json:"projections"`
 	PrimaryKey             string             `mapstructure:"primaryKey" json:"primaryKey"`
+	SoftDeleteColumn       string             `mapstructure:"softDeleteColumn" json:"softDeleteColumn"`
+	CreatedAtColumn        string             `mapstructure:"createdAtColumn" json:"createdAtColumn"`
+	UpdatedAtColumn        string             `mapstructure:"updatedAtColumn" json:"updatedAtColumn"`
 	OwningAssociations     []ModelAssociation `mapstructure:"owningAssociations" json:"owningAssociations"`
 	ReferencedAssociations []ModelAssociation `mapstructure:"referencedAssociations" json:"referencedAssociations"`
 }
@@ -34,14 +37,26 @@ type ModelAssociation struct {
 }
 
 type Populate struct {
-	Model    string       `mapstructure:"model" json:"model"`
-	As       string       `mapstructure:"as" json:"as"`
-	Project  []Projection `mapstructure:"project" json:"project"`
-	Where    Where        `mapstructure:"where" json:"where"`
-	Populate []Populate   `mapstructure:"populate" json:"populate"`
+	Model       string       `mapstructure:"model" json:"model"`
+	As          string       `mapstructure:"as" json:"as"`
+	Association string       `mapstructure:"association" json:"association"`
+	Project     []Projection `mapstructure:"project" json:"project"`
+	Where       Where        `mapstructure:"where" json:"where"`
+	Populate    []Populate   `mapstructure:"populate" json:"populate"`
 }
 
 type Where struct {
-	Template string `json:"template" mapstructure:"template"`
-	Values   []any  `json:"values" mapstructure:"values"`
+	Template  string          `json:"template" mapstructure:"template"`
+	Values    []any           `json:"values" mapstructure:"values"`
+	Condition *WhereCondition `json:"condition" mapstructure:"condition"`
+}
+
+type WhereCondition struct {
+	ConditionType string           `json:"conditionType" mapstructure:"conditionType"`
+	Conditions    []WhereCondition `json:"conditions" mapstructure:"conditions"`
+	Group         bool             `json:"group" mapstructure:"group"`
+	Field         string           `json:"field" mapstructure:"field"`
+	Operator      string           `json:"operator" mapstructure:"operator"`
+	Value         any              `json:"value" mapstructure:"value"`
+	IgnoreNull    bool             `json:"ignoreNull" mapstructure:"ignoreNull"`
 }
@@ -0,0 +1,110 @@
+package orm_schema
+
+import (
+	"fmt"
+	"ifttt/handler/common"
+	"strings"
+)
+
+type OrderBy struct {
+	Field     string `json:"field" mapstructure:"field"`
+	Direction string `json:"direction" mapstructure:"direction"`
+}
+
+type Page struct {
+	OrderBy []OrderBy
+	Limit   int
+	Offset  int
+	After   []any
+}
+
+type OrderColumn struct {
+	Column     string
+	Descending bool
+}
+
+func (p *Page) OrderColumns(model *Model, project []Projection) ([]OrderColumn, error) {
+	columns := []OrderColumn{}
+	hasPrimaryKey := false
+	if p != nil {
+		for _, o := range p.OrderBy {
+			column, ok := projectedColumn(o.Field, model, project)
+			if !ok {
+				return nil, fmt.Errorf("order by field %s not found in model %s", o.Field, model.Name)
+			}
+
+			switch strings.ToLower(o.Direction) {
+			case "", common.SortAscending:
+				columns = append(columns, OrderColumn{Column: column})
+			case common.SortDescending:
+				columns = append(columns, OrderColumn{Column: column, Descending: true})
+			default:
+				return nil, fmt.Errorf("invalid order direction %s", o.Direction)
+			}
+			hasPrimaryKey = hasPrimaryKey || column == model.PrimaryKey
+		}
+	}
+	if !hasPrimaryKey {
+		columns = append(columns, OrderColumn{Column: model.PrimaryKey})
+	}
+	return columns, nil
+}
+
+func (p *Page) paged() bool {
+	return p != nil && (p.Limit > 0 || p.Offset > 0 || len(p.After) > 0)
+}
+
+func (p *Page) limitClause(dialect string) string {
+	var clause strings.Builder
+	if p.Limit > 0 {
+		clause.WriteString(fmt.Sprintf(" LIMIT %d", p.Limit))
+	} else if p.Offset > 0 {
+		switch dialect {
+		case common.DialectMySQL:
+			clause.WriteString(" LIMIT 18446744073709551615")
+		case common.DialectSQLite:
+			clause.WriteString(" LIMIT -1")
+		}
+	}
+	if p.Offset > 0 {
+		clause.WriteString(fmt.Sprintf(" OFFSET %d", p.Offset))
+	}
+	return clause.String()
+}
+
+func projectedColumn(field string, model *Model, project []Projection) (string, bool) {
+	for _, projections := range [][]Projection{project, model.Projections} {
+		for _, p := range projections {
+			if p.As == field {
+				return p.Column, true
+			}
+		}
+	}
+	if field == model.PrimaryKey {
+		return field, true
+	}
+	return "", false
+}
+
+func BuildCount(dialect string, mainModel *Model, where *Where, withDeleted bool) (*BuiltQuery, error) {
+	queryString := fmt.Sprintf("SELECT COUNT(*) AS %s FROM %s AS %s",
+		common.SqlQuoteIdentifier(dialect, "total"),
+		common.SqlQuoteIdentifier(dialect, mainModel.Table), common.SqlQuoteIdentifier(dialect, mainModel.Name))
+
+	b := selectBuilder{dialect: dialect, withDeleted: withDeleted}
+	clause, parameters, err := b.compileWhere(where, whereScope{alias: mainModel.Name, model: mainModel})
+	if err != nil {
+		return nil, err
+	}
+	conditions := []string{}
+	if clause != "" {
+		conditions = append(conditions, clause)
+	}
+	if clause := b.softDeleteClause(mainModel.Name, mainModel); clause != "" {
+		conditions = append(conditions, clause)
+	}
+	if len(conditions) > 0 {
+		queryString += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return &BuiltQuery{QueryString: queryString, Parameters: parameters}, nil
+}
@@ -7,6 +7,11 @@ import (
 type PersistentRepository interface {
 	GetAllModels() (*[]Model, error)
 	GetAllAssociations() (*[]ModelAssociation, error)
+	SaveModels(bundle *ModelBundle, overwrite bool) ([]string, error)
+}
+
+type IntrospectionRepository interface {
+	IntrospectSchema(ctx context.Context) (*DatabaseSchema, error)
 }
 
 type CacheRepository interface {
@@ -0,0 +1,272 @@
+package orm_schema
+
+import (
+	"fmt"
+	"ifttt/handler/common"
+	"strings"
+)
+
+type BuiltQuery struct {
+	QueryString string
+	Parameters  []any
+}
+
+type selectBuilder struct {
+	dialect     string
+	withDeleted bool
+	models      map[string]*Model
+	columns     []string
+	joins       strings.Builder
+	joinParams  []any
+}
+
+func BuildSelect(
+	dialect string,
+	mainModel *Model,
+	project []Projection,
+	populate []Populate,
+	where *Where,
+	page *Page,
+	withDeleted bool,
+	models map[string]*Model,
+) (*BuiltQuery, error) {
+	b := selectBuilder{dialect: dialect, withDeleted: withDeleted, models: models}
+	alias := mainModel.Name
+
+	var orderColumns []OrderColumn
+	if page != nil {
+		var err error
+		if orderColumns, err = page.OrderColumns(mainModel, project); err != nil {
+			return nil, err
+		}
+	}
+	extra := make([]string, len(orderColumns))
+	for idx, c := range orderColumns {
+		extra[idx] = c.Column
+	}
+
+	if err := b.addColumns(alias, mainModel, project, extra...); err != nil {
+		return nil, err
+	}
+	if err := b.addJoins(alias, mainModel, populate); err != nil {
+		return nil, err
+	}
+
+	var (
+		conditions  []string
+		whereParams []any
+	)
+	scope := whereScope{alias: alias, model: mainModel, populate: populate}
+	if page.paged() {
+		scope.populate = nil
+	}
+	if clause, params, err := b.compileWhere(where, scope); err != nil {
+		return nil, err
+	} else if clause != "" {
+		conditions = append(conditions, clause)
+		whereParams = append(whereParams, params...)
+	}
+	if clause := b.softDeleteClause(alias, mainModel); clause != "" {
+		conditions = append(conditions, clause)
+	}
+
+	orderClause := ""
+	if page != nil {
+		qualified := make([]string, len(orderColumns))
+		descending := make([]bool, len(orderColumns))
+		orderBy := make([]string, len(orderColumns))
+		for idx, c := range orderColumns {
+			qualified[idx] = fmt.Sprintf("%s.%s", b.quote(alias), b.quote(c.Column))
+			descending[idx] = c.Descending
+			orderBy[idx] = qualified[idx] + " ASC"
+			if c.Descending {
+				orderBy[idx] = qualified[idx] + " DESC"
+			}
+		}
+		orderClause = " ORDER BY " + strings.Join(orderBy, ", ")
+
+		if len(page.After) > 0 {
+			if len(page.After) != len(orderColumns) {
+				return nil, fmt.Errorf("cursor expects %d values, got %d", len(orderColumns), len(page.After))
+			}
+			conditions = append(conditions, common.SqlKeysetCondition(qualified, descending, page.After,
+				func(value any) string {
+					whereParams = append(whereParams, value)
+					return "?"
+				}))
+		}
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var (
+		query      strings.Builder
+		parameters []any
+	)
+	query.WriteString("SELECT ")
+	query.WriteString(strings.Join(b.columns, ", "))
+	query.WriteString(" FROM ")
+	if page.paged() {
+		query.WriteString(fmt.Sprintf("(SELECT * FROM %s AS %s%s%s%s) AS %s",
+			b.quote(mainModel.Table), b.quote(alias), whereClause, orderClause, page.limitClause(dialect),
+			b.quote(alias)))
+		parameters = append(parameters, whereParams...)
+		query.WriteString(b.joins.String())
+		parameters = append(parameters, b.joinParams...)
+	} else {
+		query.WriteString(fmt.Sprintf("%s AS %s", b.quote(mainModel.Table), b.quote(alias)))
+		query.WriteString(b.joins.String())
+		parameters = append(parameters, b.joinParams...)
+		query.WriteString(whereClause)
+		parameters = append(parameters, whereParams...)
+	}
+	query.WriteString(orderClause)
+
+	return &BuiltQuery{QueryString: query.String(), Parameters: parameters}, nil
+}
+
+func (b *selectBuilder) quote(identifier string) string {
+	return common.SqlQuoteIdentifier(b.dialect, identifier)
+}
+
+func (b *selectBuilder) softDeleteClause(alias string, model *Model) string {
+	if b.withDeleted || model.SoftDeleteColumn == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s.%s IS NULL", b.quote(alias), b.quote(model.SoftDeleteColumn))
+}
+
+func (b *selectBuilder) addColumns(alias string, model *Model, project []Projection, extra ...string) error {
+	if model.PrimaryKey == "" {
+		return fmt.Errorf("primary key not found in model %s", model.Name)
+	}
+
+	projections := project
+	if len(projections) == 0 {
+		projections = model.Projections
+	}
+
+	selected := map[string]bool{}
+	addColumn := func(column string) {
+		if selected[column] {
+			return
+		}
+		selected[column] = true
+		b.columns = append(b.columns, fmt.Sprintf("%s.%s AS %s",
+			b.quote(alias), b.quote(column), b.quote(fmt.Sprintf("%s.%s", alias, column))))
+	}
+
+	addColumn(model.PrimaryKey)
+	for _, p := range projections {
+		addColumn(p.Column)
+	}
+	for _, column := range extra {
+		addColumn(column)
+	}
+	return nil
+}
+
+func (b *selectBuilder) addJoins(parentAlias string, parentModel *Model, populate []Populate) error {
+	for _, p := range populate {
+		childModel, ok := b.models[p.Model]
+		if !ok || childModel == nil {
+			return fmt.Errorf("model %s not found", p.Model)
+		}
+		association, err := parentModel.ResolveAssociation(childModel, p.Association)
+		if err != nil {
+			return err
+		}
+
+		childAlias := fmt.Sprintf("%s_%s", parentAlias, p.As)
+		parentColumn, childColumn := association.JoinColumns(parentModel)
+		if association.Type == common.AssociationsBelongsToMany {
+			if association.JoinTable == "" {
+				return fmt.Errorf("join table not found in association %s", association.Name)
+			}
+			joinAlias := childAlias + "__join"
+			sourceField, targetField := association.JoinTableColumns(parentModel)
+			b.joins.WriteString(fmt.Sprintf(" LEFT JOIN %s AS %s ON %s.%s = %s.%s",
+				b.quote(association.JoinTable), b.quote(joinAlias),
+				b.quote(parentAlias), b.quote(parentColumn), b.quote(joinAlias), b.quote(sourceField)))
+			b.joins.WriteString(fmt.Sprintf(" LEFT JOIN %s AS %s ON %s.%s = %s.%s",
+				b.quote(childModel.Table), b.quote(childAlias),
+				b.quote(joinAlias), b.quote(targetField), b.quote(childAlias), b.quote(childColumn)))
+		} else {
+			b.joins.WriteString(fmt.Sprintf(" LEFT JOIN %s AS %s ON %s.%s = %s.%s",
+				b.quote(childModel.Table), b.quote(childAlias),
+				b.quote(parentAlias), b.quote(parentColumn), b.quote(childAlias), b.quote(childColumn)))
+		}
+		if clause := b.softDeleteClause(childAlias, childModel); clause != "" {
+			b.joins.WriteString(" AND " + clause)
+		}
+		clause, params, err := b.compileWhere(&p.Where,
+			whereScope{alias: childAlias, model: childModel})
+		if err != nil {
+			return fmt.Errorf("invalid where for %s: %s", childAlias, err)
+		}
+		if clause != "" {
+			b.joins.WriteString(" AND " + clause)
+			b.joinParams = append(b.joinParams, params...)
+		}
+
+		if err := b.addColumns(childAlias, childModel, p.Project); err != nil {
+			return err
+		}
+		if err := b.addJoins(childAlias, childModel, p.Populate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Model) FindAssociation(join *Model) *ModelAssociation {
+	for _, a := range m.OwningAssociations {
+		if a.ReferencesModel.Name == join.Name {
+			return &a
+		}
+	}
+	for _, a := range m.ReferencedAssociations {
+		if a.OwningModel.Name == join.Name {
+			return &a
+		}
+	}
+	return nil
+}
+
+func (m *Model) ResolveAssociation(join *Model, name string) (*ModelAssociation, error) {
+	if name == "" {
+		if association := m.FindAssociation(join); association != nil {
+			return association, nil
+		}
+		return nil, fmt.Errorf("association not found between %s and %s", m.Name, join.Name)
+	}
+
+	for _, a := range m.OwningAssociations {
+		if a.Name == name && a.ReferencesModel.Name == join.Name {
+			return &a, nil
+		}
+	}
+	for _, a := range m.ReferencedAssociations {
+		if a.Name == name && a.OwningModel.Name == join.Name {
+			return &a, nil
+		}
+	}
+	return nil, fmt.Errorf("association %s not found between %s and %s", name, m.Name, join.Name)
+}
+
+func (a *ModelAssociation) JoinColumns(parent *Model) (string, string) {
+	if a.TableName == parent.Table {
+		return a.ColumnName, a.ReferencesField
+	}
+	return a.ReferencesField, a.ColumnName
+}
+
+func (a *ModelAssociation) JoinTableColumns(parent *Model) (string, string) {
+	if a.TableName == parent.Table {
+		return a.JoinTableSourceField, a.JoinTableTargetField
+	}
+	return a.JoinTableTargetField, a.JoinTableSourceField
+}
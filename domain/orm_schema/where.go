@@ -0,0 +1,140 @@
+package orm_schema
+
+import (
+	"fmt"
+	"ifttt/handler/common"
+	"reflect"
+	"strings"
+)
+
+var whereOperators = map[string]string{
+	common.ComparatorEquals:            "=",
+	common.ComparatorNotEquals:         "<>",
+	common.ComparatorLessThan:          "<",
+	common.ComparatorLessThanEquals:    "<=",
+	common.ComparatorGreaterThan:       ">",
+	common.ComparatorGreaterThanEquals: ">=",
+	common.WhereOperatorLike:           "LIKE",
+}
+
+type whereScope struct {
+	alias    string
+	model    *Model
+	populate []Populate
+}
+
+func (w *Where) Empty() bool {
+	return w == nil || (w.Template == "" && w.Condition == nil)
+}
+
+func (w *Where) validate() error {
+	if count := strings.Count(w.Template, "?"); count != len(w.Values) {
+		return fmt.Errorf("where template expects %d values, got %d", count, len(w.Values))
+	}
+	return nil
+}
+
+func (b *selectBuilder) compileWhere(where *Where, scope whereScope) (string, []any, error) {
+	if where.Empty() {
+		return "", nil, nil
+	}
+
+	var (
+		clauses    []string
+		parameters []any
+	)
+	if where.Template != "" {
+		if err := where.validate(); err != nil {
+			return "", nil, err
+		}
+		clauses = append(clauses, fmt.Sprintf("(%s)", where.Template))
+		parameters = append(parameters, where.Values...)
+	}
+	if where.Condition != nil {
+		clause, err := b.compileCondition(where.Condition, scope, &parameters)
+		if err != nil {
+			return "", nil, err
+		}
+		if clause != "" {
+			clauses = append(clauses, fmt.Sprintf("(%s)", clause))
+		}
+	}
+	return strings.Join(clauses, " AND "), parameters, nil
+}
+
+func (b *selectBuilder) compileCondition(condition *WhereCondition, scope whereScope, parameters *[]any) (string, error) {
+	if condition.Group {
+		join := strings.ToUpper(condition.ConditionType)
+		if join == "" {
+			join = common.WhereConditionAnd
+		}
+		if join != common.WhereConditionAnd && join != common.WhereConditionOr {
+			return "", fmt.Errorf("condition type not in (%s,%s)", common.WhereConditionAnd, common.WhereConditionOr)
+		}
+
+		var clauses []string
+		for idx := range condition.Conditions {
+			clause, err := b.compileCondition(&condition.Conditions[idx], scope, parameters)
+			if err != nil {
+				return "", err
+			}
+			if clause != "" {
+				clauses = append(clauses, fmt.Sprintf("(%s)", clause))
+			}
+		}
+		return strings.Join(clauses, fmt.Sprintf(" %s ", join)), nil
+	}
+
+	if condition.Field == "" {
+		return "", fmt.Errorf("field is required in where condition")
+	}
+	field, err := b.resolveFieldIn(condition.Field, scope.alias, scope.model, nil, scope.populate)
+	if err != nil {
+		return "", err
+	}
+	column := field.qualified
+
+	operator := condition.Operator
+	if operator == "" {
+		operator = common.ComparatorEquals
+	}
+
+	switch operator {
+	case common.WhereOperatorNull:
+		return column + " IS NULL", nil
+	case common.WhereOperatorNotNull:
+		return column + " IS NOT NULL", nil
+	case common.ComparatorIn, common.ComparatorNotIn:
+		rv := reflect.ValueOf(condition.Value)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return "", fmt.Errorf("operator %s expects a list for field %s", operator, condition.Field)
+		}
+		if rv.Len() == 0 {
+			if operator == common.ComparatorIn {
+				return "1 = 0", nil
+			}
+			return "1 = 1", nil
+		}
+		*parameters = append(*parameters, condition.Value)
+		if operator == common.ComparatorIn {
+			return column + " IN (?)", nil
+		}
+		return column + " NOT IN (?)", nil
+	}
+
+	sqlOperator, ok := whereOperators[operator]
+	if !ok {
+		return "", fmt.Errorf("where operator %s not supported", operator)
+	}
+	if condition.Value == nil {
+		switch operator {
+		case common.ComparatorEquals:
+			return column + " IS NULL", nil
+		case common.ComparatorNotEquals:
+			return column + " IS NOT NULL", nil
+		}
+		return "", fmt.Errorf("operator %s does not accept null for field %s", operator, condition.Field)
+	}
+	*parameters = append(*parameters, condition.Value)
+	return fmt.Sprintf("%s %s ?", column, sqlOperator), nil
+}
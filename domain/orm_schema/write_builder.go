@@ -0,0 +1,127 @@
+package orm_schema
+
+import (
+	"fmt"
+	"ifttt/handler/common"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (m *Model) MapColumns(row map[string]any, partial bool) (map[string]any, error) {
+	projections := make(map[string]*Projection, len(m.Projections))
+	for idx := range m.Projections {
+		projections[m.Projections[idx].As] = &m.Projections[idx]
+	}
+
+	mapped := make(map[string]any, len(row))
+	for as, val := range row {
+		p, ok := projections[as]
+		if !ok {
+			return nil, fmt.Errorf("column %s not found in model %s", as, m.Name)
+		}
+		if schemaVal, err := p.ToSchemaValue(val); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %s", as, err)
+		} else {
+			mapped[p.Column] = schemaVal
+		}
+	}
+
+	if !partial {
+		for _, p := range m.Projections {
+			if _, ok := mapped[p.Column]; !ok && p.NotNull && p.Column != m.PrimaryKey && !m.isTimestamp(p.Column) {
+				return nil, fmt.Errorf("column %s is required in model %s", p.As, m.Name)
+			}
+		}
+	}
+
+	if len(mapped) == 0 {
+		return nil, fmt.Errorf("no columns provided for model %s", m.Name)
+	}
+	return mapped, nil
+}
+
+func (m *Model) Stamp(columns map[string]any, now time.Time, insert bool) {
+	stamp := func(column string) {
+		if _, ok := columns[column]; column != "" && !ok {
+			columns[column] = now
+		}
+	}
+	if insert {
+		stamp(m.CreatedAtColumn)
+	}
+	stamp(m.UpdatedAtColumn)
+}
+
+func (m *Model) isTimestamp(column string) bool {
+	return column == m.CreatedAtColumn || column == m.UpdatedAtColumn || column == m.SoftDeleteColumn
+}
+
+func BuildInsert(dialect string, model *Model, columns map[string]any) *BuiltQuery {
+	names, placeholders, parameters := sortedColumns(columns)
+	for idx, name := range names {
+		names[idx] = common.SqlQuoteIdentifier(dialect, name)
+		placeholders[idx] = "?"
+	}
+
+	queryString := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		common.SqlQuoteIdentifier(dialect, model.Table), strings.Join(names, ", "), strings.Join(placeholders, ", "))
+	if dialect != common.DialectMySQL {
+		queryString += " RETURNING " + common.SqlQuoteIdentifier(dialect, model.PrimaryKey)
+	}
+	return &BuiltQuery{QueryString: queryString, Parameters: parameters}
+}
+
+func BuildUpdate(dialect string, model *Model, columns map[string]any, keys []any) *BuiltQuery {
+	names, assignments, parameters := sortedColumns(columns)
+	for idx, name := range names {
+		assignments[idx] = fmt.Sprintf("%s = ?", common.SqlQuoteIdentifier(dialect, name))
+	}
+
+	return &BuiltQuery{
+		QueryString: fmt.Sprintf("UPDATE %s SET %s WHERE %s IN (?)",
+			common.SqlQuoteIdentifier(dialect, model.Table), strings.Join(assignments, ", "),
+			common.SqlQuoteIdentifier(dialect, model.PrimaryKey)),
+		Parameters: append(parameters, keys),
+	}
+}
+
+func BuildDelete(dialect string, model *Model, keys []any, now time.Time) *BuiltQuery {
+	if model.SoftDeleteColumn != "" {
+		columns := map[string]any{model.SoftDeleteColumn: now}
+		if model.UpdatedAtColumn != "" {
+			columns[model.UpdatedAtColumn] = now
+		}
+		built := BuildUpdate(dialect, model, columns, keys)
+		built.QueryString += fmt.Sprintf(" AND %s IS NULL", common.SqlQuoteIdentifier(dialect, model.SoftDeleteColumn))
+		return built
+	}
+
+	return &BuiltQuery{
+		QueryString: fmt.Sprintf("DELETE FROM %s WHERE %s IN (?)",
+			common.SqlQuoteIdentifier(dialect, model.Table), common.SqlQuoteIdentifier(dialect, model.PrimaryKey)),
+		Parameters: []any{keys},
+	}
+}
+
+func KeysWhere(dialect string, model *Model, keys []any) *Where {
+	return &Where{
+		Template: fmt.Sprintf("%s.%s IN (?)",
+			common.SqlQuoteIdentifier(dialect, model.Name), common.SqlQuoteIdentifier(dialect, model.PrimaryKey)),
+		Values: []any{keys},
+	}
+}
+
+func sortedColumns(columns map[string]any) ([]string, []string, []any) {
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parameters := make([]any, len(names))
+	for idx, name := range names {
+		parameters[idx] = columns[name]
+	}
+	return names, make([]string, len(names)), parameters
+}
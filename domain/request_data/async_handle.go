@@ -0,0 +1,51 @@
+package request_data
+
+import (
+	"context"
+	"fmt"
+)
+
+type AsyncHandle struct {
+	Name   string
+	done   chan struct{}
+	result any
+	err    error
+}
+
+func (r *RequestData) RegisterAsyncHandle(name string) (*AsyncHandle, error) {
+	r.Mtx.Lock()
+	defer r.Mtx.Unlock()
+
+	if _, ok := r.asyncHandles[name]; ok {
+		return nil, fmt.Errorf("async handle %s already registered", name)
+	}
+	handle := &AsyncHandle{Name: name, done: make(chan struct{})}
+	r.asyncHandles[name] = handle
+	return handle, nil
+}
+
+func (r *RequestData) GetAsyncHandle(name string) (*AsyncHandle, bool) {
+	r.Mtx.Lock()
+	defer r.Mtx.Unlock()
+
+	handle, ok := r.asyncHandles[name]
+	return handle, ok
+}
+
+func (h *AsyncHandle) Complete(result any, err error) {
+	if h == nil {
+		return
+	}
+	h.result = result
+	h.err = err
+	close(h.done)
+}
+
+func (h *AsyncHandle) Wait(ctx context.Context) (any, error) {
+	select {
+	case <-h.done:
+		return h.result, h.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("stopped waiting for async handle %s: %s", h.Name, context.Cause(ctx))
+	}
+}
@@ -15,6 +15,7 @@ func NewRequestData() *RequestData {
 	r.AggregatedResponse = make(map[string]any)
 	r.Store = make(map[string]any)
 	r.ExternalTrips = []ExternalTrip{}
+	r.asyncHandles = make(map[string]*AsyncHandle)
 	return &r
 }
 
@@ -11,6 +11,7 @@ type RequestData struct {
 	AggregatedResponse map[string]any    `json:"aggregatedResponse" mapstructure:"aggregatedResponse"`
 	Store              map[string]any    `json:"store" mapstructure:"store"`
 	ExternalTrips      []ExternalTrip    `json:"externalTrips" mapstructure:"externalTrips"`
+	asyncHandles       map[string]*AsyncHandle
 }
 
 type ExternalTrip struct {
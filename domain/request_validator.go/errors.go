@@ -1,9 +1,30 @@
 package requestvalidator
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ErrorCodeMissing   = "missing"
+	ErrorCodeRequired  = "required"
+	ErrorCodeType      = "type"
+	ErrorCodeRegex     = "regex"
+	ErrorCodeCharset   = "charset"
+	ErrorCodeMinLength = "minLength"
+	ErrorCodeMaxLength = "maxLength"
+	ErrorCodeMinimum   = "minimum"
+	ErrorCodeMaximum   = "maximum"
+	ErrorCodeIn        = "in"
+	ErrorCodeMinItems  = "minItems"
+	ErrorCodeMaxItems  = "maxItems"
+	ErrorCodeInternal  = "internal"
+)
 
 type ValidationError struct {
 	Internal  bool
+	Field     string
+	Code      string
 	ErrorInfo error
 }
 
@@ -15,10 +36,44 @@ func (v *ValidationError) Error() string {
 	}
 }
 
+func (v *ValidationError) Detail() map[string]any {
+	return map[string]any{
+		"field":   v.Field,
+		"code":    v.Code,
+		"message": v.ErrorInfo.Error(),
+	}
+}
+
 func Normalize(vErr []ValidationError) []error {
 	e := make([]error, 0, len(vErr))
 	for _, v := range vErr {
-		e = append(e, v.ErrorInfo)
+		if v.Field == "" {
+			e = append(e, v.ErrorInfo)
+		} else {
+			e = append(e, fmt.Errorf("%s: %s", v.Field, v.ErrorInfo))
+		}
 	}
 	return e
 }
+
+func Details(vErr []ValidationError) []any {
+	details := make([]any, 0, len(vErr))
+	for _, v := range vErr {
+		details = append(details, v.Detail())
+	}
+	return details
+}
+
+func newValidationError(field string, code string, format string, args ...any) ValidationError {
+	return ValidationError{
+		Internal:  code == ErrorCodeInternal,
+		Field:     field,
+		Code:      code,
+		ErrorInfo: fmt.Errorf(format, args...),
+	}
+}
+
+func pointerPath(parent string, token any) string {
+	escaped := strings.NewReplacer("~", "~0", "/", "~1").Replace(fmt.Sprint(token))
+	return parent + "/" + escaped
+}
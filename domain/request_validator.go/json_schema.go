@@ -0,0 +1,352 @@
+package requestvalidator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+const (
+	jsonSchemaInternalTag = "x-internal-tag"
+	jsonSchemaMaxDepth    = 64
+	jsonSchemaURL         = "mem://request.json"
+)
+
+// keywords whose values are subschemas, everything else (const, enum,
+// default, examples, ...) holds plain data and is never walked for tags
+var (
+	jsonSchemaKeywords = []string{"not", "if", "then", "else", "items", "additionalItems", "contains",
+		"additionalProperties", "propertyNames", "unevaluatedItems", "unevaluatedProperties", "contentSchema"}
+	jsonSchemaArrayKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems", "items"}
+	jsonSchemaMapKeywords   = []string{"properties", "patternProperties", "dependentSchemas", "dependencies", "$defs", "definitions"}
+)
+
+type JSONSchema struct {
+	mtx      sync.Mutex
+	root     any
+	compiler *jsonschema.Compiler
+	schema   *jsonschema.Schema
+	branches map[string]*jsonschema.Schema
+}
+
+var jsonSchemaPrinter = message.NewPrinter(language.English)
+
+func CompileJSONSchema(schema map[string]any) (*JSONSchema, error) {
+	marshalled, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	root, err := jsonschema.UnmarshalJSON(bytes.NewReader(marshalled))
+	if err != nil {
+		return nil, err
+	}
+	if err := validateJSONSchemaTags(root, 0); err != nil {
+		return nil, err
+	}
+
+	compiled := &JSONSchema{
+		root:     root,
+		compiler: jsonschema.NewCompiler(),
+		branches: map[string]*jsonschema.Schema{},
+	}
+	compiled.compiler.DefaultDraft(jsonschema.Draft2020)
+	if err := compiled.compiler.AddResource(jsonSchemaURL, root); err != nil {
+		return nil, err
+	}
+	if compiled.schema, err = compiled.compiler.Compile(jsonSchemaURL); err != nil {
+		return nil, err
+	}
+	return compiled, nil
+}
+
+func ValidateJSONSchema(schema *JSONSchema, m *map[string]any,
+	scan func(tagName string, value any) error) []ValidationError {
+	if schema == nil {
+		return []ValidationError{newValidationError("", ErrorCodeInternal, "json schema not compiled")}
+	}
+
+	var instance any = map[string]any{}
+	if m != nil {
+		instance = *m
+	}
+	if err := schema.schema.Validate(instance); err != nil {
+		var vErr *jsonschema.ValidationError
+		if !errors.As(err, &vErr) {
+			return []ValidationError{newValidationError("", ErrorCodeInternal, "could not validate json schema: %s", err)}
+		}
+		validationErrors := jsonSchemaErrors(vErr)
+		sort.SliceStable(validationErrors, func(i, j int) bool {
+			return validationErrors[i].Field < validationErrors[j].Field
+		})
+		return validationErrors
+	}
+
+	if err := schema.scanTags(schema.root, "", instance, scan, 0); err != nil {
+		return []ValidationError{newValidationError("", ErrorCodeInternal, "could not scan request parameter: %s", err)}
+	}
+	return nil
+}
+
+func JSONSchemaInternalTags(schema any) []string {
+	tags := []string{}
+	if s, ok := schema.(map[string]any); ok {
+		if tag, ok := s[jsonSchemaInternalTag].(string); ok {
+			tags = append(tags, tag)
+		}
+		for _, subschema := range jsonSubschemas(s) {
+			tags = append(tags, JSONSchemaInternalTags(subschema)...)
+		}
+	}
+	return tags
+}
+
+func validateJSONSchemaTags(schema any, depth int) error {
+	if depth > jsonSchemaMaxDepth {
+		return fmt.Errorf("json schema nested deeper than %d levels", jsonSchemaMaxDepth)
+	}
+	s, ok := schema.(map[string]any)
+	if !ok {
+		return nil
+	}
+	if val, ok := s[jsonSchemaInternalTag]; ok {
+		if tag, ok := val.(string); !ok || tag == "" {
+			return fmt.Errorf("%s must be a non empty string", jsonSchemaInternalTag)
+		}
+	}
+	for _, subschema := range jsonSubschemas(s) {
+		if err := validateJSONSchemaTags(subschema, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func jsonSubschemas(schema map[string]any) []any {
+	subschemas := []any{}
+	for _, keyword := range jsonSchemaKeywords {
+		if val, ok := schema[keyword].(map[string]any); ok {
+			subschemas = append(subschemas, val)
+		}
+	}
+	for _, keyword := range jsonSchemaArrayKeywords {
+		if val, ok := schema[keyword].([]any); ok {
+			subschemas = append(subschemas, val...)
+		}
+	}
+	for _, keyword := range jsonSchemaMapKeywords {
+		if val, ok := schema[keyword].(map[string]any); ok {
+			for _, subschema := range val {
+				subschemas = append(subschemas, subschema)
+			}
+		}
+	}
+	return subschemas
+}
+
+func (c *JSONSchema) matches(location string, instance any) (bool, error) {
+	c.mtx.Lock()
+	schema, ok := c.branches[location]
+	if !ok {
+		var err error
+		if schema, err = c.compiler.Compile(jsonSchemaURL + "#" + location); err != nil {
+			c.mtx.Unlock()
+			return false, err
+		}
+		c.branches[location] = schema
+	}
+	c.mtx.Unlock()
+	return schema.Validate(instance) == nil, nil
+}
+
+func (c *JSONSchema) resolve(ref string) (any, string, bool) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, "", false
+	}
+	fragment, err := url.PathUnescape(strings.TrimPrefix(ref, "#"))
+	if err != nil || (fragment != "" && !strings.HasPrefix(fragment, "/")) {
+		return nil, "", false
+	}
+	node := c.root
+	for _, token := range strings.Split(fragment, "/")[1:] {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch n := node.(type) {
+		case map[string]any:
+			next, ok := n[token]
+			if !ok {
+				return nil, "", false
+			}
+			node = next
+		case []any:
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(n) {
+				return nil, "", false
+			}
+			node = n[idx]
+		default:
+			return nil, "", false
+		}
+	}
+	return node, fragment, true
+}
+
+func (c *JSONSchema) scanTags(node any, location string, instance any,
+	scan func(tagName string, value any) error, depth int) error {
+	if depth > jsonSchemaMaxDepth {
+		return fmt.Errorf("json schema nested deeper than %d levels", jsonSchemaMaxDepth)
+	}
+	schema, ok := node.(map[string]any)
+	if !ok {
+		return nil
+	}
+	walk := func(child any, childLocation string, childInstance any) error {
+		return c.scanTags(child, childLocation, childInstance, scan, depth+1)
+	}
+
+	if tag, ok := schema[jsonSchemaInternalTag].(string); ok {
+		if err := scan(tag, instance); err != nil {
+			return err
+		}
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		if target, targetLocation, ok := c.resolve(ref); ok {
+			if err := walk(target, targetLocation, instance); err != nil {
+				return err
+			}
+		}
+	}
+
+	if branches, ok := schema["allOf"].([]any); ok {
+		for idx, branch := range branches {
+			if err := walk(branch, pointerPath(location+"/allOf", idx), instance); err != nil {
+				return err
+			}
+		}
+	}
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		branches, ok := schema[keyword].([]any)
+		if !ok {
+			continue
+		}
+		for idx, branch := range branches {
+			branchLocation := pointerPath(location+"/"+keyword, idx)
+			if matched, err := c.matches(branchLocation, instance); err != nil {
+				return err
+			} else if matched {
+				if err := walk(branch, branchLocation, instance); err != nil {
+					return err
+				}
+				if keyword == "oneOf" {
+					break
+				}
+			}
+		}
+	}
+	if _, ok := schema["if"]; ok {
+		matched, err := c.matches(location+"/if", instance)
+		if err != nil {
+			return err
+		}
+		if branch, ok := schema["then"]; ok && matched {
+			if err := walk(branch, location+"/then", instance); err != nil {
+				return err
+			}
+		} else if branch, ok := schema["else"]; ok && !matched {
+			if err := walk(branch, location+"/else", instance); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch inst := instance.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		patterns, _ := schema["patternProperties"].(map[string]any)
+		for key, val := range inst {
+			matched := false
+			if property, ok := properties[key]; ok {
+				matched = true
+				if err := walk(property, pointerPath(location+"/properties", key), val); err != nil {
+					return err
+				}
+			}
+			for pattern, property := range patterns {
+				if re, err := regexp.Compile(pattern); err == nil && re.MatchString(key) {
+					matched = true
+					if err := walk(property, pointerPath(location+"/patternProperties", pattern), val); err != nil {
+						return err
+					}
+				}
+			}
+			if additional, ok := schema["additionalProperties"]; ok && !matched {
+				if err := walk(additional, location+"/additionalProperties", val); err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		prefixItems, _ := schema["prefixItems"].([]any)
+		for idx, val := range inst {
+			if idx < len(prefixItems) {
+				if err := walk(prefixItems[idx], pointerPath(location+"/prefixItems", idx), val); err != nil {
+					return err
+				}
+			} else if items, ok := schema["items"]; ok {
+				if err := walk(items, location+"/items", val); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func jsonSchemaErrors(vErr *jsonschema.ValidationError) []ValidationError {
+	if len(vErr.Causes) > 0 {
+		validationErrors := []ValidationError{}
+		for _, cause := range vErr.Causes {
+			validationErrors = append(validationErrors, jsonSchemaErrors(cause)...)
+		}
+		return validationErrors
+	}
+
+	path := ""
+	for _, token := range vErr.InstanceLocation {
+		path = pointerPath(path, token)
+	}
+	code := ErrorCodeInternal
+	if keywords := vErr.ErrorKind.KeywordPath(); len(keywords) > 0 {
+		code = keywords[len(keywords)-1]
+	}
+	msg := vErr.ErrorKind.LocalizedString(jsonSchemaPrinter)
+
+	switch k := vErr.ErrorKind.(type) {
+	case *kind.Required:
+		validationErrors := make([]ValidationError, 0, len(k.Missing))
+		for _, missing := range k.Missing {
+			validationErrors = append(validationErrors,
+				newValidationError(pointerPath(path, missing), ErrorCodeMissing, "key %s missing in request", missing))
+		}
+		return validationErrors
+	case *kind.AdditionalProperties:
+		validationErrors := make([]ValidationError, 0, len(k.Properties))
+		for _, property := range k.Properties {
+			validationErrors = append(validationErrors,
+				newValidationError(pointerPath(path, property), code, "additional property %s not allowed", property))
+		}
+		return validationErrors
+	}
+	return []ValidationError{newValidationError(path, code, "%s", msg)}
+}
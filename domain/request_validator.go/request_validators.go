@@ -1,5 +1,7 @@
 package requestvalidator
 
+import "github.com/mitchellh/mapstructure"
+
 const (
 	dataTypeText    = "text"
 	dataTypeNumber  = "number"
@@ -18,7 +20,7 @@ type RequestParameter struct {
 
 type textValue struct {
 	Alpha   bool  `json:"alpha" mapstructure:"alpha"`
-	Numeric bool  `json:"alphanumeric" mapstructure:"numeric"`
+	Numeric bool  `json:"numeric" mapstructure:"numeric"`
 	Special bool  `json:"special" mapstructure:"special"`
 	Minimum int   `json:"minimum" mapstructure:"minimum"`
 	Maximum int   `json:"maximum" mapstructure:"maximum"`
@@ -26,9 +28,9 @@ type textValue struct {
 }
 
 type numberValue struct {
-	Minimum int   `json:"minimum" mapstructure:"minimum"`
-	Maximum int   `json:"maximum" mapstructure:"maximum"`
-	In      []any `json:"in" mapstructure:"in"`
+	Minimum *float64 `json:"minimum" mapstructure:"minimum"`
+	Maximum *float64 `json:"maximum" mapstructure:"maximum"`
+	In      []any    `json:"in" mapstructure:"in"`
 }
 
 type booleanValue struct {
@@ -41,3 +43,26 @@ type arrayValue struct {
 }
 
 type mapValue map[string]RequestParameter
+
+func (p *RequestParameter) InternalTags() []string {
+	tags := []string{}
+	if p.InternalTag != "" {
+		tags = append(tags, p.InternalTag)
+	}
+
+	switch p.DataType {
+	case dataTypeArray:
+		validator := arrayValue{}
+		if err := mapstructure.Decode(p.Config, &validator); err == nil && validator.OfType != nil {
+			tags = append(tags, validator.OfType.InternalTags()...)
+		}
+	case dataTypeMap:
+		validator := mapValue{}
+		if err := mapstructure.Decode(p.Config, &validator); err == nil {
+			for _, nested := range validator {
+				tags = append(tags, nested.InternalTags()...)
+			}
+		}
+	}
+	return tags
+}
@@ -3,8 +3,11 @@ package requestvalidator
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"sync"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/samber/lo"
@@ -12,14 +15,25 @@ import (
 
 func ValidateMap(schema *map[string]RequestParameter, m *map[string]any,
 	scan func(tagName string, value any) error) []ValidationError {
-	var wg sync.WaitGroup
+	validationErrors := validateMap(schema, m, "", scan)
+	sort.SliceStable(validationErrors, func(i, j int) bool {
+		return validationErrors[i].Field < validationErrors[j].Field
+	})
+	return validationErrors
+}
+
+func validateMap(schema *map[string]RequestParameter, m *map[string]any, path string,
+	scan func(tagName string, value any) error) []ValidationError {
+	var (
+		wg  sync.WaitGroup
+		mtx sync.Mutex
+	)
 	validationErrors := []ValidationError{}
 
 	absentFromRequest, _ := lo.Difference(lo.Keys(*schema), lo.Keys(*m))
 	for _, key := range absentFromRequest {
-		validationErrors = append(validationErrors, ValidationError{
-			ErrorInfo: fmt.Errorf("key %s missing in request", key),
-		})
+		validationErrors = append(validationErrors,
+			newValidationError(pointerPath(path, key), ErrorCodeMissing, "key %s missing in request", key))
 	}
 	if len(validationErrors) != 0 {
 		return validationErrors
@@ -30,12 +44,10 @@ func ValidateMap(schema *map[string]RequestParameter, m *map[string]any,
 		go func(key string, val any) {
 			defer wg.Done()
 			if s, ok := (*schema)[key]; ok {
-				if err := s.validateValue(val, scan); err != nil {
-					for _, err := range err {
-						validationErrors = append(validationErrors, ValidationError{
-							ErrorInfo: fmt.Errorf("validation for key %s failed: %s", key, err.ErrorInfo),
-						})
-					}
+				if err := s.validateValue(val, pointerPath(path, key), scan); len(err) != 0 {
+					mtx.Lock()
+					validationErrors = append(validationErrors, err...)
+					mtx.Unlock()
 				}
 			}
 		}(key, val)
@@ -45,10 +57,11 @@ func ValidateMap(schema *map[string]RequestParameter, m *map[string]any,
 	return validationErrors
 }
 
-func (s *RequestParameter) validateValue(val any, scan func(tagName string, value any) error) []ValidationError {
+func (s *RequestParameter) validateValue(val any, path string,
+	scan func(tagName string, value any) error) []ValidationError {
 	if val == nil {
 		if s.Required {
-			return []ValidationError{{ErrorInfo: fmt.Errorf("key is required")}}
+			return []ValidationError{newValidationError(path, ErrorCodeRequired, "key is required")}
 		}
 		return nil
 	}
@@ -58,91 +71,162 @@ func (s *RequestParameter) validateValue(val any, scan func(tagName string, valu
 		switch val.(type) {
 		case string:
 			if s.DataType != dataTypeText {
-				return []ValidationError{{ErrorInfo: fmt.Errorf("invalid datatype, requires %s", s.DataType)}}
+				return []ValidationError{newValidationError(path, ErrorCodeType, "invalid datatype, requires %s", s.DataType)}
 			}
 		case uint, int, float32, float64:
 			if s.DataType != dataTypeNumber {
-				return []ValidationError{{ErrorInfo: fmt.Errorf("invalid datatype, requires %s", s.DataType)}}
+				return []ValidationError{newValidationError(path, ErrorCodeType, "invalid datatype, requires %s", s.DataType)}
 			}
 		case bool:
 			if s.DataType != dataTypeBoolean {
-				return []ValidationError{{ErrorInfo: fmt.Errorf("invalid datatype, requires %s", s.DataType)}}
+				return []ValidationError{newValidationError(path, ErrorCodeType, "invalid datatype, requires %s", s.DataType)}
 			}
 		default:
-			return []ValidationError{{ErrorInfo: fmt.Errorf("invalid datatype: unambigous")}}
+			return []ValidationError{newValidationError(path, ErrorCodeType, "invalid datatype: unambigous")}
 		}
-		validationRegex := regexp.MustCompile(s.Regex)
 		stringifiedVal := fmt.Sprint(val)
-		matches := validationRegex.FindStringSubmatch(stringifiedVal)
-		if len(matches) == 0 || matches[0] != stringifiedVal {
-			return []ValidationError{{ErrorInfo: fmt.Errorf("regex validation failed")}}
+		if s.Regex != "" {
+			validationRegex, err := regexp.Compile(s.Regex)
+			if err != nil {
+				return []ValidationError{newValidationError(path, ErrorCodeInternal, "could not compile regex: %s", err)}
+			}
+			matches := validationRegex.FindStringSubmatch(stringifiedVal)
+			if len(matches) == 0 || matches[0] != stringifiedVal {
+				return []ValidationError{newValidationError(path, ErrorCodeRegex, "regex validation failed")}
+			}
 		}
-		if s.DataType == dataTypeNumber {
+		switch s.DataType {
+		case dataTypeText:
+			validator := textValue{}
+			if err := mapstructure.Decode(s.Config, &validator); err != nil {
+				return []ValidationError{newValidationError(path, ErrorCodeInternal, "could not decode validator")}
+			}
+			if err := validator.validate(stringifiedVal, path); len(err) != 0 {
+				return err
+			}
+		case dataTypeNumber:
 			numVal, err := strconv.ParseFloat(stringifiedVal, 64)
 			if err != nil {
-				return []ValidationError{
-					{ErrorInfo: fmt.Errorf("could not convert number to float64"), Internal: true}}
+				return []ValidationError{newValidationError(path, ErrorCodeInternal, "could not convert number to float64")}
 			}
 			validator := numberValue{}
 			if err := mapstructure.Decode(s.Config, &validator); err != nil {
-				return []ValidationError{
-					{ErrorInfo: fmt.Errorf("could not decode validator"), Internal: true}}
-			} else if float64(validator.Minimum) > numVal || float64(validator.Maximum) < numVal {
-				return []ValidationError{
-					{ErrorInfo: fmt.Errorf("number not within range %d - %d", validator.Minimum, validator.Maximum)}}
+				return []ValidationError{newValidationError(path, ErrorCodeInternal, "could not decode validator")}
+			}
+			if err := validator.validate(numVal, path); len(err) != 0 {
+				return err
 			}
 		}
 		if err := scan(s.InternalTag, val); err != nil {
-			return []ValidationError{{ErrorInfo: fmt.Errorf("could not scan request parameter: %s", err)}}
+			return []ValidationError{newValidationError(path, ErrorCodeInternal, "could not scan request parameter: %s", err)}
 		}
 	case dataTypeArray:
 		arr, ok := val.([]any)
 		if !ok {
-			return []ValidationError{{ErrorInfo: fmt.Errorf("invalid datatype, requires %s", s.DataType)}}
+			return []ValidationError{newValidationError(path, ErrorCodeType, "invalid datatype, requires %s", s.DataType)}
 		}
 		validator := arrayValue{}
 		if err := mapstructure.Decode(s.Config, &validator); err != nil {
-			return []ValidationError{
-				{ErrorInfo: fmt.Errorf("could not decode validator"), Internal: true}}
+			return []ValidationError{newValidationError(path, ErrorCodeInternal, "could not decode validator")}
 		}
 		if err := scan(s.InternalTag, val); err != nil {
-			return []ValidationError{{ErrorInfo: fmt.Errorf("could not scan request parameter: %s", err)}}
+			return []ValidationError{newValidationError(path, ErrorCodeInternal, "could not scan request parameter: %s", err)}
 		}
-		return validator.validate(arr, scan)
+		return validator.validate(arr, path, scan)
 	case dataTypeMap:
 		mapVal, ok := val.(map[string]any)
 		if !ok {
-			return []ValidationError{{ErrorInfo: fmt.Errorf("invalid datatype, requires %s", s.DataType)}}
+			return []ValidationError{newValidationError(path, ErrorCodeType, "invalid datatype, requires %s", s.DataType)}
 		}
 		validator := mapValue{}
 		if err := mapstructure.Decode(s.Config, &validator); err != nil {
-			return []ValidationError{
-				{ErrorInfo: fmt.Errorf("could not decode validator"), Internal: true}}
+			return []ValidationError{newValidationError(path, ErrorCodeInternal, "could not decode validator")}
 		}
 		if err := scan(s.InternalTag, val); err != nil {
-			return []ValidationError{{ErrorInfo: fmt.Errorf("could not scan request parameter: %s", err)}}
+			return []ValidationError{newValidationError(path, ErrorCodeInternal, "could not scan request parameter: %s", err)}
 		}
-		return ValidateMap((*map[string]RequestParameter)(&validator), &mapVal, scan)
+		return validateMap((*map[string]RequestParameter)(&validator), &mapVal, path, scan)
 	}
 	return nil
 }
 
-func (s *arrayValue) validate(arr []any, scan func(tagName string, value any) error) []ValidationError {
-	var wg sync.WaitGroup
+func (s *textValue) validate(val string, path string) []ValidationError {
+	validationErrors := []ValidationError{}
+
+	if s.Alpha || s.Numeric || s.Special {
+		for _, r := range val {
+			isAlpha, isNumeric := unicode.IsLetter(r), unicode.IsDigit(r)
+			if (isAlpha && !s.Alpha) || (isNumeric && !s.Numeric) || (!isAlpha && !isNumeric && !s.Special) {
+				validationErrors = append(validationErrors,
+					newValidationError(path, ErrorCodeCharset, "character %q not allowed", r))
+				break
+			}
+		}
+	}
+
+	length := utf8.RuneCountInString(val)
+	if s.Minimum > 0 && length < s.Minimum {
+		validationErrors = append(validationErrors,
+			newValidationError(path, ErrorCodeMinLength, "length must be at least %d", s.Minimum))
+	}
+	if s.Maximum > 0 && length > s.Maximum {
+		validationErrors = append(validationErrors,
+			newValidationError(path, ErrorCodeMaxLength, "length must be at most %d", s.Maximum))
+	}
+
+	if len(s.In) > 0 && !lo.ContainsBy(s.In, func(allowed any) bool { return fmt.Sprint(allowed) == val }) {
+		validationErrors = append(validationErrors,
+			newValidationError(path, ErrorCodeIn, "value must be one of %v", s.In))
+	}
+	return validationErrors
+}
+
+func (s *numberValue) validate(val float64, path string) []ValidationError {
+	validationErrors := []ValidationError{}
+
+	if s.Minimum != nil && val < *s.Minimum {
+		validationErrors = append(validationErrors,
+			newValidationError(path, ErrorCodeMinimum, "number must be at least %v", *s.Minimum))
+	}
+	if s.Maximum != nil && val > *s.Maximum {
+		validationErrors = append(validationErrors,
+			newValidationError(path, ErrorCodeMaximum, "number must be at most %v", *s.Maximum))
+	}
+
+	if len(s.In) > 0 && !lo.ContainsBy(s.In, func(allowed any) bool {
+		allowedVal, err := strconv.ParseFloat(fmt.Sprint(allowed), 64)
+		return err == nil && allowedVal == val
+	}) {
+		validationErrors = append(validationErrors,
+			newValidationError(path, ErrorCodeIn, "value must be one of %v", s.In))
+	}
+	return validationErrors
+}
+
+func (s *arrayValue) validate(arr []any, path string, scan func(tagName string, value any) error) []ValidationError {
+	var (
+		wg  sync.WaitGroup
+		mtx sync.Mutex
+	)
 	validationErrors := []ValidationError{}
 
-	if s.Maximum > 0 && len(arr) < s.Maximum {
-		return []ValidationError{{ErrorInfo: fmt.Errorf("invalid length of array")}}
-	} else if s.Minimum > 0 && len(arr) < s.Minimum {
-		return []ValidationError{{ErrorInfo: fmt.Errorf("invalid length of array")}}
+	if s.Minimum > 0 && len(arr) < s.Minimum {
+		return []ValidationError{newValidationError(path, ErrorCodeMinItems, "array must have at least %d items", s.Minimum)}
+	} else if s.Maximum > 0 && len(arr) > s.Maximum {
+		return []ValidationError{newValidationError(path, ErrorCodeMaxItems, "array must have at most %d items", s.Maximum)}
+	}
+	if s.OfType == nil {
+		return nil
 	}
 
 	for i, item := range arr {
 		wg.Add(1)
 		go func(i int, item any) {
 			defer wg.Done()
-			if err := s.OfType.validateValue(item, scan); err != nil {
+			if err := s.OfType.validateValue(item, pointerPath(path, i), scan); len(err) != 0 {
+				mtx.Lock()
 				validationErrors = append(validationErrors, err...)
+				mtx.Unlock()
 			}
 		}(i, item)
 	}
@@ -0,0 +1,209 @@
+package resolvable
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"ifttt/handler/common"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+type apiAuth struct {
+	Type              string         `json:"type" mapstructure:"type"`
+	TokenURL          Resolvable     `json:"tokenUrl" mapstructure:"tokenUrl"`
+	ClientID          Resolvable     `json:"clientId" mapstructure:"clientId"`
+	ClientSecret      Resolvable     `json:"clientSecret" mapstructure:"clientSecret"`
+	Scopes            []string       `json:"scopes" mapstructure:"scopes"`
+	Params            map[string]any `json:"params" mapstructure:"params"`
+	CredentialsInBody bool           `json:"credentialsInBody" mapstructure:"credentialsInBody"`
+	CacheKey          string         `json:"cacheKey" mapstructure:"cacheKey"`
+	RefreshBefore     uint           `json:"refreshBefore" mapstructure:"refreshBefore"`
+	DefaultTTL        uint           `json:"defaultTtl" mapstructure:"defaultTtl"`
+	Timeout           uint           `json:"timeout" mapstructure:"timeout"`
+}
+
+type oAuthClient struct {
+	tokenURL          string
+	clientID          string
+	clientSecret      string
+	scopes            []string
+	params            map[string]string
+	credentialsInBody bool
+	cacheKey          string
+	refreshBefore     uint
+	defaultTTL        uint
+	timeout           uint
+	appCacheRepo      AppCacheRepository
+}
+
+type oAuthTokenResponse struct {
+	AccessToken string `json:"access_token" mapstructure:"access_token"`
+	TokenType   string `json:"token_type" mapstructure:"token_type"`
+	ExpiresIn   uint   `json:"expires_in" mapstructure:"expires_in"`
+}
+
+var oAuthTokenGroup singleflight.Group
+
+func (a *apiAuth) createClient(ctx context.Context, dependencies map[common.IntIota]any) (*oAuthClient, error) {
+	if a.Type != common.ApiAuthOAuth2ClientCredentials {
+		return nil, fmt.Errorf("auth type %s not found", a.Type)
+	}
+
+	appCacheRepo, ok := dependencies[common.DependencyAppCacheRepo].(AppCacheRepository)
+	if !ok {
+		return nil, fmt.Errorf("could not cast app cache repo")
+	}
+
+	client := oAuthClient{
+		scopes:            a.Scopes,
+		params:            make(map[string]string, len(a.Params)),
+		credentialsInBody: a.CredentialsInBody,
+		refreshBefore:     a.RefreshBefore,
+		defaultTTL:        a.DefaultTTL,
+		timeout:           a.Timeout,
+		appCacheRepo:      appCacheRepo,
+	}
+	if client.refreshBefore == 0 {
+		client.refreshBefore = common.ApiAuthDefaultRefreshBefore
+	}
+	if client.defaultTTL == 0 {
+		client.defaultTTL = common.ApiAuthDefaultTokenTTL
+	}
+	if client.timeout == 0 {
+		client.timeout = common.ApiAuthDefaultTimeout
+	}
+
+	if tokenURL, err := a.TokenURL.Resolve(ctx, dependencies); err != nil {
+		return nil, fmt.Errorf("could not resolve token url: %s", err)
+	} else {
+		client.tokenURL = fmt.Sprint(tokenURL)
+	}
+	if clientID, err := a.ClientID.Resolve(ctx, dependencies); err != nil {
+		return nil, fmt.Errorf("could not resolve client id: %s", err)
+	} else {
+		client.clientID = fmt.Sprint(clientID)
+	}
+	if clientSecret, err := a.ClientSecret.Resolve(ctx, dependencies); err != nil {
+		return nil, fmt.Errorf("could not resolve client secret: %s", err)
+	} else {
+		client.clientSecret = fmt.Sprint(clientSecret)
+	}
+
+	if paramsResolved, err := resolveMapMaybeParallel(&a.Params, ctx, dependencies); err != nil {
+		return nil, fmt.Errorf("could not resolve token params: %s", err)
+	} else {
+		for k, v := range paramsResolved {
+			client.params[k] = fmt.Sprint(v)
+		}
+	}
+
+	if a.CacheKey != "" {
+		client.cacheKey = a.CacheKey
+	} else {
+		scopes := append([]string{}, client.scopes...)
+		sort.Strings(scopes)
+		client.cacheKey = fmt.Sprintf("%s:%s", common.ApiAuthCachePrefix, common.GetMD5Hash(
+			strings.Join([]string{client.tokenURL, client.clientID, strings.Join(scopes, " ")}, "|"),
+		))
+	}
+
+	return &client, nil
+}
+
+func (o *oAuthClient) getToken(forceRefresh bool, ctx context.Context) (string, error) {
+	if !forceRefresh {
+		if cached, err := o.appCacheRepo.GetKey(o.cacheKey, ctx); err != nil {
+			common.LogWithTracer(common.LogSystem, "could not read cached token", err.Error(), true, ctx)
+		} else if cached != nil && fmt.Sprint(cached) != "" {
+			return fmt.Sprint(cached), nil
+		}
+	}
+
+	token, err, _ := oAuthTokenGroup.Do(o.cacheKey, func() (any, error) {
+		return o.fetchToken(context.WithoutCancel(ctx))
+	})
+	if err != nil {
+		return "", err
+	}
+	return token.(string), nil
+}
+
+func (o *oAuthClient) invalidateToken(ctx context.Context) {
+	if _, err := o.appCacheRepo.DeleteKey(o.cacheKey, ctx); err != nil {
+		common.LogWithTracer(common.LogSystem, "could not invalidate cached token", err.Error(), true, ctx)
+	}
+}
+
+func (o *oAuthClient) fetchToken(ctx context.Context) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(o.scopes) > 0 {
+		form.Set("scope", strings.Join(o.scopes, " "))
+	}
+	for k, v := range o.params {
+		form.Set(k, v)
+	}
+	if o.credentialsInBody {
+		form.Set("client_id", o.clientID)
+		form.Set("client_secret", o.clientSecret)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(o.timeout)*time.Millisecond)
+	defer cancel()
+
+	httpRequest, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, o.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("could not create token request: %s", err)
+	}
+	httpRequest.Header.Set(common.ResponseHeaderContentType, "application/x-www-form-urlencoded")
+	httpRequest.Header.Set("Accept", "application/json")
+	if !o.credentialsInBody {
+		httpRequest.SetBasicAuth(url.QueryEscape(o.clientID), url.QueryEscape(o.clientSecret))
+	}
+
+	res, err := http.DefaultClient.Do(httpRequest)
+	if err != nil {
+		return "", fmt.Errorf("could not execute token request: %s", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("could not read token response: %s", err)
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return "", fmt.Errorf("token endpoint returned %s", res.Status)
+	}
+
+	var tokenResponse oAuthTokenResponse
+	if err := json.Unmarshal(body, &tokenResponse); err != nil {
+		return "", fmt.Errorf("could not parse token response: %s", err)
+	} else if tokenResponse.AccessToken == "" {
+		return "", fmt.Errorf("access token missing in token response")
+	} else if tokenResponse.TokenType != "" && !strings.EqualFold(tokenResponse.TokenType, "bearer") {
+		return "", fmt.Errorf("token type %s not supported", tokenResponse.TokenType)
+	}
+
+	ttl := o.defaultTTL
+	if tokenResponse.ExpiresIn > 0 {
+		ttl = 0
+		if tokenResponse.ExpiresIn > o.refreshBefore {
+			ttl = tokenResponse.ExpiresIn - o.refreshBefore
+		}
+	}
+	if ttl > 0 {
+		if err := o.appCacheRepo.SetKey(o.cacheKey, tokenResponse.AccessToken, ttl, ctx); err != nil {
+			common.LogWithTracer(common.LogSystem, "could not cache token", err.Error(), true, ctx)
+		}
+	}
+
+	return tokenResponse.AccessToken, nil
+}
@@ -1,13 +1,15 @@
 package resolvable
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
-	"encoding/xml"
 	"fmt"
 	"ifttt/handler/common"
 	"ifttt/handler/domain/request_data"
 	"io"
+	"mime"
 	"net/http"
 	"strings"
 	"time"
@@ -15,6 +17,7 @@ import (
 	"github.com/fatih/structs"
 	"github.com/mitchellh/mapstructure"
 	"github.com/samber/lo"
+	"golang.org/x/net/html/charset"
 )
 
 type apiCall struct {
@@ -23,13 +26,20 @@ type apiCall struct {
 	Headers map[string]any `json:"headers" mapstructure:"headers"`
 	Body    map[string]any `json:"body" mapstructure:"body"`
 	Async   bool           `json:"async" mapstructure:"async"`
+	Handle  string         `json:"handle" mapstructure:"handle"`
 	Timeout uint           `json:"timeout" mapstructure:"timeout"`
+	Auth    *apiAuth       `json:"auth" mapstructure:"auth"`
+	Decode  string         `json:"decode" mapstructure:"decode"`
+	MaxSize uint           `json:"maxResponseSize" mapstructure:"maxResponseSize"`
 }
 
 type callData struct {
 	Metadata *apiMetadata     `json:"metadata" mapstructure:"metadata"`
 	Request  *apiRequest      `json:"request" mapstructure:"request"`
 	Response *apiCallResponse `json:"response" mapstructure:"response"`
+	auth     *oAuthClient
+	decode   string
+	maxSize  uint
 }
 
 type apiRequest struct {
@@ -44,6 +54,8 @@ type apiCallResponse struct {
 	Status     string              `json:"status" mapstructure:"status"`
 	Headers    map[string][]string `json:"headers" mapstructure:"headers"`
 	Body       any                 `json:"body" mapstructure:"body"`
+	MediaType  string              `json:"mediaType" mapstructure:"mediaType"`
+	Size       int                 `json:"size" mapstructure:"size"`
 }
 
 type apiMetadata struct {
@@ -54,6 +66,7 @@ type apiMetadata struct {
 	DidTimeout bool      `json:"didTimeout" mapstructure:"didTimeout"`
 	Async      bool      `json:"async" mapstructure:"async"`
 	Error      string    `json:"error" mapstructure:"error"`
+	AuthRetry  bool      `json:"authRetry" mapstructure:"authRetry"`
 }
 
 func (a *apiCall) Resolve(ctx context.Context, dependencies map[common.IntIota]any) (any, error) {
@@ -63,7 +76,18 @@ func (a *apiCall) Resolve(ctx context.Context, dependencies map[common.IntIota]a
 	}
 
 	if a.Async {
-		go callData.doRequest(ctx)
+		handle, err := registerAsyncHandle(a.Handle, ctx)
+		if err != nil {
+			return nil, err
+		}
+		go func() {
+			if err := callData.doRequest(ctx); err != nil {
+				handle.Complete(nil, err)
+			} else {
+				handle.Complete(callData, nil)
+			}
+		}()
+		return asyncHandleDescriptor(a.Handle), nil
 	} else if err := callData.doRequest(ctx); err != nil {
 		return nil, err
 	}
@@ -80,6 +104,21 @@ func (a *apiCall) createCallData(ctx context.Context, dependencies map[common.In
 	}
 	callData.Request = request
 	callData.Response = &apiCallResponse{}
+	callData.decode = a.Decode
+	if callData.decode == "" {
+		callData.decode = common.ApiDecodeAuto
+	}
+	callData.maxSize = a.MaxSize
+	if callData.maxSize == 0 {
+		callData.maxSize = common.ApiDefaultMaxResponseSize
+	}
+	if a.Auth != nil {
+		if auth, err := a.Auth.createClient(ctx, dependencies); err != nil {
+			return nil, fmt.Errorf("could not create auth client: %s", err)
+		} else {
+			callData.auth = auth
+		}
+	}
 	return &callData, nil
 }
 
@@ -93,6 +132,13 @@ func (a *apiCall) createMetadata() *apiMetadata {
 func (a *apiCall) createRequest(ctx context.Context, dependencies map[common.IntIota]any) (*apiRequest, error) {
 	var request apiRequest
 
+	switch a.Decode {
+	case "", common.ApiDecodeAuto, common.ApiDecodeJSON, common.ApiDecodeXML,
+		common.ApiDecodeText, common.ApiDecodeBinary:
+	default:
+		return nil, fmt.Errorf("response decoder %s not found", a.Decode)
+	}
+
 	allowedMethods := []string{"GET", "POST"}
 	if !lo.Contains(allowedMethods, a.Method) {
 		return nil, fmt.Errorf("request method %s not found", a.Method)
@@ -147,20 +193,23 @@ func (c *callData) doRequest(ctx context.Context) error {
 			fmt.Sprintf("%s:%s", c.Request.Method, c.Request.URL), &mapped, c.Metadata.TimeTaken, ctx)
 	}()
 
-	httpRequest, err := c.Request.createHttpRequest()
-	if err != nil {
-		return fmt.Errorf("error in creating http request: %s", err)
-	}
+	requestCtx := ctx
 	if c.Metadata.Timeout > 0 {
 		timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(c.Metadata.Timeout)*time.Millisecond)
-		httpRequest = httpRequest.WithContext(timeoutCtx)
+		requestCtx = timeoutCtx
 		defer cancel()
 	}
 
 	c.Metadata.Start = time.Now()
-	res, err := http.DefaultClient.Do(httpRequest)
+	res, err := c.sendHttpRequest(false, requestCtx)
+	if err == nil && res.StatusCode == http.StatusUnauthorized && c.auth != nil {
+		res.Body.Close()
+		c.Metadata.AuthRetry = true
+		c.auth.invalidateToken(ctx)
+		res, err = c.sendHttpRequest(true, requestCtx)
+	}
 	if err != nil {
-		if httpRequest.Context().Err() == context.DeadlineExceeded {
+		if requestCtx.Err() == context.DeadlineExceeded {
 			c.Metadata.DidTimeout = true
 		} else {
 			common.LogWithTracer(common.LogUser, "error in executing api call", err, true, ctx)
@@ -181,6 +230,24 @@ func (c *callData) doRequest(ctx context.Context) error {
 	return nil
 }
 
+func (c *callData) sendHttpRequest(refreshToken bool, ctx context.Context) (*http.Response, error) {
+	httpRequest, err := c.Request.createHttpRequest()
+	if err != nil {
+		return nil, fmt.Errorf("error in creating http request: %s", err)
+	}
+	httpRequest = httpRequest.WithContext(ctx)
+
+	if c.auth != nil {
+		if token, err := c.auth.getToken(refreshToken, ctx); err != nil {
+			return nil, fmt.Errorf("could not get auth token: %s", err)
+		} else {
+			httpRequest.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	return http.DefaultClient.Do(httpRequest)
+}
+
 func (c *callData) createResponse(res *http.Response) (*apiCallResponse, error) {
 	var response apiCallResponse
 
@@ -193,33 +260,93 @@ func (c *callData) createResponse(res *http.Response) (*apiCallResponse, error)
 	}
 	response.Headers = respHeadersMap
 
-	if err := response.readResponseBody(res); err != nil {
+	if err := response.readResponseBody(res, c.decode, c.maxSize); err != nil {
 		return nil, err
 	}
 
 	return &response, nil
 }
 
-func (a *apiCallResponse) readResponseBody(res *http.Response) error {
+func (a *apiCallResponse) readResponseBody(res *http.Response, decode string, maxSize uint) error {
 	defer res.Body.Close()
 
-	body, err := io.ReadAll(res.Body)
+	body, err := io.ReadAll(io.LimitReader(res.Body, int64(maxSize)+1))
 	if err != nil {
 		return fmt.Errorf("could not read io/response: %s", err)
+	} else if len(body) > int(maxSize) {
+		return fmt.Errorf("response body exceeds maximum size of %d bytes", maxSize)
 	}
+	a.Size = len(body)
 
-	switch res.Header.Get("Content-Type") {
-	case "application/json":
+	mediaType, params, err := mime.ParseMediaType(res.Header.Get(common.ResponseHeaderContentType))
+	if err != nil {
+		mediaType, params, _ = mime.ParseMediaType(http.DetectContentType(body))
+	}
+	a.MediaType = mediaType
+
+	if len(body) == 0 {
+		a.Body = nil
+		return nil
+	}
+
+	if decode == common.ApiDecodeAuto {
+		decode = detectDecoder(mediaType)
+	}
+
+	switch decode {
+	case common.ApiDecodeJSON:
 		if err := json.Unmarshal(body, &a.Body); err != nil {
 			return fmt.Errorf("error parsing JSON: %s", err)
 		}
-	case "application/xml", "text/xml":
-		if err := xml.Unmarshal(body, &a.Body); err != nil {
+	case common.ApiDecodeXML:
+		if decoded, err := common.XmlToMap(body); err != nil {
 			return fmt.Errorf("error parsing XML: %s", err)
+		} else {
+			a.Body = decoded
+		}
+	case common.ApiDecodeText:
+		if text, err := decodeCharset(body, params["charset"]); err != nil {
+			return fmt.Errorf("error decoding text: %s", err)
+		} else {
+			a.Body = text
 		}
 	default:
-		a.Body = string(body)
+		a.Body = map[string]any{
+			"base64":    base64.StdEncoding.EncodeToString(body),
+			"size":      len(body),
+			"mediaType": mediaType,
+		}
 	}
 
 	return nil
 }
+
+func detectDecoder(mediaType string) string {
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return common.ApiDecodeJSON
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return common.ApiDecodeXML
+	case strings.HasPrefix(mediaType, "text/"),
+		mediaType == "application/javascript",
+		mediaType == "application/x-www-form-urlencoded":
+		return common.ApiDecodeText
+	default:
+		return common.ApiDecodeBinary
+	}
+}
+
+func decodeCharset(body []byte, label string) (string, error) {
+	if label == "" || strings.EqualFold(label, "utf-8") || strings.EqualFold(label, "utf8") {
+		return string(body), nil
+	}
+	reader, err := charset.NewReaderLabel(label, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
@@ -0,0 +1,68 @@
+package resolvable
+
+import (
+	"context"
+	"fmt"
+	"ifttt/handler/common"
+	"ifttt/handler/domain/request_data"
+	"time"
+)
+
+type await struct {
+	Handles []string `json:"handles" mapstructure:"handles"`
+	Timeout uint     `json:"timeout" mapstructure:"timeout"`
+}
+
+func (a *await) Resolve(ctx context.Context, dependencies map[common.IntIota]any) (any, error) {
+	if len(a.Handles) == 0 {
+		return nil, fmt.Errorf("no handles to await")
+	}
+
+	reqData := request_data.GetRequestData(ctx)
+	handles := make([]*request_data.AsyncHandle, 0, len(a.Handles))
+	for _, name := range a.Handles {
+		if handle, ok := reqData.GetAsyncHandle(name); !ok {
+			return nil, fmt.Errorf("async handle %s not found", name)
+		} else {
+			handles = append(handles, handle)
+		}
+	}
+
+	if a.Timeout > 0 {
+		timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(a.Timeout)*time.Millisecond)
+		defer cancel()
+		ctx = timeoutCtx
+	}
+
+	results := make(map[string]any, len(handles))
+	for _, handle := range handles {
+		if result, err := handle.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("async handle %s failed: %s", handle.Name, err)
+		} else {
+			results[handle.Name] = result
+		}
+	}
+
+	if len(handles) == 1 {
+		return results[handles[0].Name], nil
+	}
+	return results, nil
+}
+
+// async work runs on the request context, so calls that are never awaited
+// are cancelled once the request finishes
+func registerAsyncHandle(name string, ctx context.Context) (*request_data.AsyncHandle, error) {
+	if name == "" {
+		return nil, nil
+	}
+	return request_data.GetRequestData(ctx).RegisterAsyncHandle(name)
+}
+
+// asyncHandleDescriptor is what an async call resolves to, the result itself
+// is still being written and is only reachable through await
+func asyncHandleDescriptor(name string) any {
+	if name == "" {
+		return nil
+	}
+	return map[string]any{"handle": name}
+}
@@ -0,0 +1,29 @@
+package resolvable
+
+import (
+	"fmt"
+	"ifttt/handler/common"
+	"strings"
+)
+
+type DataSource struct {
+	Name         string
+	RawQueryRepo RawQueryRepository
+	Timeout      uint
+}
+
+func getDataSource(name string, dependencies map[common.IntIota]any) (*DataSource, error) {
+	dataSources, ok := dependencies[common.DependencyDataSources].(map[string]*DataSource)
+	if !ok {
+		return nil, fmt.Errorf("could not cast data sources")
+	}
+
+	if name == "" {
+		name = common.DataSourceDefault
+	}
+	dataSource, ok := dataSources[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("data source %s not found", name)
+	}
+	return dataSource, nil
+}
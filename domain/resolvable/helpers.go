@@ -32,6 +32,8 @@ func resolvableFactory(rType string) resolvableInterface {
 		return &setLog{}
 	case accessorResponse:
 		return &Response{}
+	case accessorRaiseError:
+		return &raiseError{}
 	case accessorStringInterpolation:
 		return &stringInterpolation{}
 	case accessorEncode:
@@ -60,6 +62,10 @@ func resolvableFactory(rType string) resolvableInterface {
 		return &dateIntervals{}
 	case accessorConditional:
 		return &conditional{}
+	case accessorAwait:
+		return &await{}
+	case accessorTransaction:
+		return &transaction{}
 	default:
 		return nil
 	}
@@ -0,0 +1,57 @@
+package resolvable
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"ifttt/handler/common"
+	"ifttt/handler/domain/orm_schema"
+)
+
+func (o *orm) runAggregate(
+	tx *sql.Tx,
+	dataSource *DataSource,
+	mainModel *orm_schema.Model,
+	modelsInUse *map[string]*orm_schema.Model,
+	ctx context.Context,
+	dependencies map[common.IntIota]any,
+) (*queryData, error) {
+	where, err := resolveWhere(o.Where, ctx, dependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	var populate []orm_schema.Populate
+	if o.Populate != nil {
+		if populate, err = resolvePopulate(*o.Populate, ctx, dependencies); err != nil {
+			return nil, err
+		}
+	}
+
+	built, columns, err := orm_schema.BuildAggregate(
+		dataSource.RawQueryRepo.Dialect(), mainModel, populate, where, o.GroupBy, o.Aggregates, o.WithDeleted, *modelsInUse)
+	if err != nil {
+		return nil, fmt.Errorf("could not build aggregate: %s", err)
+	}
+
+	queryData, err := o.runBuilt(tx, dataSource, built, true, ctx, dependencies)
+	if err != nil {
+		return nil, err
+	} else if queryData.Results == nil {
+		return queryData, nil
+	}
+
+	typed := make([]map[string]any, len(*queryData.Results))
+	for idx, row := range *queryData.Results {
+		typed[idx] = make(map[string]any, len(columns))
+		for _, c := range columns {
+			if val, err := c.Sanitize(row[c.Label]); err != nil {
+				return nil, fmt.Errorf("could not read %s: %s", c.Label, err)
+			} else {
+				typed[idx][c.Label] = val
+			}
+		}
+	}
+	queryData.Results = &typed
+	return queryData, nil
+}
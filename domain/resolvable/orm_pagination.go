@@ -0,0 +1,117 @@
+package resolvable
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"ifttt/handler/common"
+	"ifttt/handler/domain/orm_schema"
+)
+
+type ormPager struct {
+	limit      int
+	pkAccessor string
+	accessors  []string
+	count      *orm_schema.BuiltQuery
+}
+
+func (o *orm) createPage(
+	mainModel *orm_schema.Model, where *orm_schema.Where, dialect string,
+	ctx context.Context, dependencies map[common.IntIota]any,
+) (*orm_schema.Page, *ormPager, error) {
+	if o.Limit < 0 || o.Offset < 0 {
+		return nil, nil, fmt.Errorf("limit and offset cannot be negative")
+	}
+
+	page := orm_schema.Page{OrderBy: o.OrderBy, Offset: o.Offset}
+	var project []orm_schema.Projection
+	if o.Project != nil {
+		project = *o.Project
+	}
+	orderColumns, err := page.OrderColumns(mainModel, project)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pager := ormPager{
+		limit:      o.Limit,
+		pkAccessor: fmt.Sprintf("%s.%s", mainModel.Name, mainModel.PrimaryKey),
+		accessors:  make([]string, len(orderColumns)),
+	}
+	for idx, c := range orderColumns {
+		pager.accessors[idx] = fmt.Sprintf("%s.%s", mainModel.Name, c.Column)
+	}
+	if o.Limit > 0 {
+		page.Limit = o.Limit + 1
+	}
+
+	cursor, err := resolveMaybe(o.Cursor, ctx, dependencies)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not resolve cursor: %s", err)
+	} else if cursor != nil && fmt.Sprint(cursor) != "" {
+		if page.After, err = decodeCursor(fmt.Sprint(cursor), len(orderColumns)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if o.Count {
+		if pager.count, err = orm_schema.BuildCount(dialect, mainModel, where, o.WithDeleted); err != nil {
+			return nil, nil, fmt.Errorf("could not build count: %s", err)
+		}
+	}
+
+	return &page, &pager, nil
+}
+
+func (p *ormPager) apply(
+	tx *sql.Tx, dataSource *DataSource, data *queryData, ctx context.Context, dependencies map[common.IntIota]any,
+) error {
+	page := queryPage{}
+
+	if p.limit > 0 && data.Results != nil {
+		var (
+			seen    = map[any]bool{}
+			lastRow map[string]any
+			trimmed = make([]map[string]any, 0, len(*data.Results))
+		)
+		for _, row := range *data.Results {
+			key := row[p.pkAccessor]
+			if !seen[key] {
+				if len(seen) == p.limit {
+					page.HasMore = true
+					continue
+				}
+				seen[key] = true
+				lastRow = row
+			}
+			trimmed = append(trimmed, row)
+		}
+
+		if page.HasMore && lastRow != nil {
+			values := make([]any, len(p.accessors))
+			for idx, accessor := range p.accessors {
+				values[idx] = lastRow[accessor]
+			}
+			cursor, err := encodeCursor(values)
+			if err != nil {
+				return err
+			}
+			page.NextCursor = cursor
+		}
+		data.Results = &trimmed
+	}
+
+	if p.count != nil {
+		q := query{QueryString: p.count.QueryString, Scan: true}
+		countData, err := q.init(tx, dataSource, &queryParameters{positional: p.count.Parameters}, ctx, dependencies)
+		if err != nil {
+			return fmt.Errorf("could not count results: %s", err)
+		} else if countData.Results == nil || len(*countData.Results) == 0 {
+			return fmt.Errorf("count returned no rows")
+		}
+		page.Total = (*countData.Results)[0]["total"]
+	}
+
+	data.Page = &page
+	return nil
+}
@@ -0,0 +1,413 @@
+package resolvable
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"ifttt/handler/common"
+	"ifttt/handler/domain/orm_schema"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+func (o *orm) generateQuery(
+	mainModel *orm_schema.Model,
+	modelsInUse *map[string]*orm_schema.Model,
+	dialect string,
+	ctx context.Context,
+	dependencies map[common.IntIota]any,
+) (*query, *queryParameters, *ormPager, error) {
+	if o.Operation != common.OrmSelect {
+		return nil, nil, nil, fmt.Errorf("query generation not supported for operation %s", o.Operation)
+	}
+
+	where, err := resolveWhere(o.Where, ctx, dependencies)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var populate []orm_schema.Populate
+	if o.Populate != nil {
+		if populate, err = resolvePopulate(*o.Populate, ctx, dependencies); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	var project []orm_schema.Projection
+	if o.Project != nil {
+		project = *o.Project
+	}
+
+	page, pager, err := o.createPage(mainModel, where, dialect, ctx, dependencies)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	selectQuery, err := orm_schema.BuildSelect(
+		dialect, mainModel, project, populate, where, page, o.WithDeleted, *modelsInUse)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("could not build select: %s", err)
+	}
+
+	return &query{QueryString: selectQuery.QueryString, Scan: true},
+		&queryParameters{positional: selectQuery.Parameters}, pager, nil
+}
+
+func (o *orm) modelNames() []string {
+	names := []string{o.Model}
+	if o.ModelsInUse != nil {
+		names = append(names, *o.ModelsInUse...)
+	}
+	if o.Populate != nil {
+		names = append(names, populateModelNames(*o.Populate)...)
+	}
+	return names
+}
+
+func populateModelNames(populate []orm_schema.Populate) []string {
+	names := []string{}
+	for _, p := range populate {
+		names = append(names, p.Model)
+		names = append(names, populateModelNames(p.Populate)...)
+	}
+	return names
+}
+
+func resolveWhere(
+	where *orm_schema.Where, ctx context.Context, dependencies map[common.IntIota]any,
+) (*orm_schema.Where, error) {
+	if where == nil {
+		return nil, nil
+	}
+
+	values, err := ResolveArrayMaybe(&where.Values, ctx, dependencies)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve where values: %s", err)
+	}
+
+	var condition *orm_schema.WhereCondition
+	if where.Condition != nil {
+		if condition, err = resolveWhereCondition(*where.Condition, ctx, dependencies); err != nil {
+			return nil, fmt.Errorf("could not resolve where condition: %s", err)
+		}
+	}
+	return &orm_schema.Where{Template: where.Template, Values: values, Condition: condition}, nil
+}
+
+func resolveWhereCondition(
+	condition orm_schema.WhereCondition, ctx context.Context, dependencies map[common.IntIota]any,
+) (*orm_schema.WhereCondition, error) {
+	if condition.Group {
+		conditions := []orm_schema.WhereCondition{}
+		for _, c := range condition.Conditions {
+			resolved, err := resolveWhereCondition(c, ctx, dependencies)
+			if err != nil {
+				return nil, err
+			}
+			if resolved != nil {
+				conditions = append(conditions, *resolved)
+			}
+		}
+		if len(conditions) == 0 {
+			return nil, nil
+		}
+		condition.Conditions = conditions
+		return &condition, nil
+	}
+
+	if condition.Operator == common.WhereOperatorNull || condition.Operator == common.WhereOperatorNotNull {
+		return &condition, nil
+	}
+	value, err := resolveMaybe(condition.Value, ctx, dependencies)
+	if err != nil {
+		return nil, fmt.Errorf("field %s: %s", condition.Field, err)
+	}
+	if value == nil && condition.IgnoreNull {
+		return nil, nil
+	}
+	condition.Value = value
+	return &condition, nil
+}
+
+func resolvePopulate(
+	populate []orm_schema.Populate, ctx context.Context, dependencies map[common.IntIota]any,
+) ([]orm_schema.Populate, error) {
+	resolved := make([]orm_schema.Populate, len(populate))
+	for idx, p := range populate {
+		where, err := resolveWhere(&p.Where, ctx, dependencies)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve populate %s: %s", p.As, err)
+		}
+		children, err := resolvePopulate(p.Populate, ctx, dependencies)
+		if err != nil {
+			return nil, err
+		}
+
+		resolved[idx] = p
+		resolved[idx].Where = *where
+		resolved[idx].Populate = children
+	}
+	return resolved, nil
+}
+
+func (o *orm) runWrite(
+	tx *sql.Tx,
+	dataSource *DataSource,
+	mainModel *orm_schema.Model,
+	modelsInUse *map[string]*orm_schema.Model,
+	ctx context.Context,
+	dependencies map[common.IntIota]any,
+) (*queryData, error) {
+	var (
+		keys     []any
+		affected int
+	)
+
+	switch o.Operation {
+	case common.OrmInsert:
+		rows, err := o.resolveColumnRows(mainModel, false, ctx, dependencies)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		for _, row := range rows {
+			mainModel.Stamp(row, now, true)
+			if key, err := o.insertRow(tx, dataSource, mainModel, row, ctx, dependencies); err != nil {
+				return nil, err
+			} else {
+				keys = append(keys, key)
+				affected++
+			}
+		}
+	case common.OrmUpdate, common.OrmDelete:
+		where, err := resolveWhere(o.Where, ctx, dependencies)
+		if err != nil {
+			return nil, err
+		}
+		if where.Empty() {
+			return nil, fmt.Errorf("where is required for %s", o.Operation)
+		}
+		if keys, err = o.selectKeys(tx, dataSource, mainModel, where, modelsInUse, ctx, dependencies); err != nil {
+			return nil, err
+		}
+
+		if o.Operation == common.OrmDelete {
+			deleted, err := o.selectByKeys(tx, dataSource, mainModel, keys, modelsInUse, ctx, dependencies)
+			if err != nil {
+				return nil, err
+			}
+			if len(keys) > 0 {
+				dialect := dataSource.RawQueryRepo.Dialect()
+				if deleted.Metadata.RowsAffected, err = o.execBuilt(
+					tx, dataSource, orm_schema.BuildDelete(dialect, mainModel, keys, time.Now()), ctx, dependencies,
+				); err != nil {
+					return nil, err
+				}
+			}
+			return deleted, nil
+		}
+
+		rows, err := o.resolveColumnRows(mainModel, true, ctx, dependencies)
+		if err != nil {
+			return nil, err
+		} else if len(rows) != 1 {
+			return nil, fmt.Errorf("update expects a single columns object")
+		}
+		mainModel.Stamp(rows[0], time.Now(), false)
+		if len(keys) > 0 {
+			dialect := dataSource.RawQueryRepo.Dialect()
+			if affected, err = o.execBuilt(
+				tx, dataSource, orm_schema.BuildUpdate(dialect, mainModel, rows[0], keys), ctx, dependencies,
+			); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported write operation: %s", o.Operation)
+	}
+
+	result, err := o.selectByKeys(tx, dataSource, mainModel, keys, modelsInUse, ctx, dependencies)
+	if err != nil {
+		return nil, err
+	}
+	result.Metadata.RowsAffected = affected
+	return result, nil
+}
+
+func (o *orm) resolveColumnRows(
+	model *orm_schema.Model, partial bool, ctx context.Context, dependencies map[common.IntIota]any,
+) ([]map[string]any, error) {
+	resolved, err := resolveMaybe(o.Columns, ctx, dependencies)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve columns: %s", err)
+	}
+
+	var rows []map[string]any
+	switch r := resolved.(type) {
+	case map[string]any:
+		rows = []map[string]any{r}
+	case []any:
+		for _, row := range r {
+			if rowMap, ok := row.(map[string]any); ok {
+				rows = append(rows, rowMap)
+			} else {
+				return nil, fmt.Errorf("columns row is not an object")
+			}
+		}
+	default:
+		return nil, fmt.Errorf("columns must be an object or an array of objects")
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no columns provided")
+	}
+
+	mapped := make([]map[string]any, len(rows))
+	for idx, row := range rows {
+		if mapped[idx], err = model.MapColumns(row, partial); err != nil {
+			return nil, err
+		}
+	}
+	return mapped, nil
+}
+
+func (o *orm) insertRow(
+	tx *sql.Tx,
+	dataSource *DataSource,
+	model *orm_schema.Model,
+	row map[string]any,
+	ctx context.Context,
+	dependencies map[common.IntIota]any,
+) (any, error) {
+	dialect := dataSource.RawQueryRepo.Dialect()
+	built := orm_schema.BuildInsert(dialect, model, row)
+
+	if dialect != common.DialectMySQL {
+		queryData, err := o.runBuilt(tx, dataSource, built, true, ctx, dependencies)
+		if err != nil {
+			return nil, err
+		} else if queryData.Results == nil || len(*queryData.Results) == 0 {
+			return nil, fmt.Errorf("insert into %s returned no key", model.Table)
+		}
+		return (*queryData.Results)[0][model.PrimaryKey], nil
+	}
+
+	if _, err := o.execBuilt(tx, dataSource, built, ctx, dependencies); err != nil {
+		return nil, err
+	} else if key, ok := row[model.PrimaryKey]; ok {
+		return key, nil
+	}
+
+	queryData, err := o.runBuilt(tx, dataSource, &orm_schema.BuiltQuery{
+		QueryString: "SELECT LAST_INSERT_ID() AS " + common.SqlQuoteIdentifier(dialect, model.PrimaryKey),
+	}, true, ctx, dependencies)
+	if err != nil {
+		return nil, err
+	} else if queryData.Results == nil || len(*queryData.Results) == 0 {
+		return nil, fmt.Errorf("could not get last insert id for %s", model.Table)
+	}
+	return (*queryData.Results)[0][model.PrimaryKey], nil
+}
+
+func (o *orm) selectKeys(
+	tx *sql.Tx,
+	dataSource *DataSource,
+	model *orm_schema.Model,
+	where *orm_schema.Where,
+	modelsInUse *map[string]*orm_schema.Model,
+	ctx context.Context,
+	dependencies map[common.IntIota]any,
+) ([]any, error) {
+	built, err := orm_schema.BuildSelect(dataSource.RawQueryRepo.Dialect(), model,
+		[]orm_schema.Projection{{Column: model.PrimaryKey, As: model.PrimaryKey}},
+		nil, where, nil, o.WithDeleted, *modelsInUse)
+	if err != nil {
+		return nil, fmt.Errorf("could not build key select: %s", err)
+	}
+
+	queryData, err := o.runBuilt(tx, dataSource, built, true, ctx, dependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	accessor := fmt.Sprintf("%s.%s", model.Name, model.PrimaryKey)
+	keys := []any{}
+	if queryData.Results != nil {
+		for _, row := range *queryData.Results {
+			if key := row[accessor]; key != nil {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return lo.Uniq(keys), nil
+}
+
+func (o *orm) selectByKeys(
+	tx *sql.Tx,
+	dataSource *DataSource,
+	model *orm_schema.Model,
+	keys []any,
+	modelsInUse *map[string]*orm_schema.Model,
+	ctx context.Context,
+	dependencies map[common.IntIota]any,
+) (*queryData, error) {
+	dialect := dataSource.RawQueryRepo.Dialect()
+
+	var populate []orm_schema.Populate
+	if o.Populate != nil {
+		var err error
+		if populate, err = resolvePopulate(*o.Populate, ctx, dependencies); err != nil {
+			return nil, err
+		}
+	}
+	var project []orm_schema.Projection
+	if o.Project != nil {
+		project = *o.Project
+	}
+
+	built, err := orm_schema.BuildSelect(dialect, model, project, populate,
+		orm_schema.KeysWhere(dialect, model, keys), &orm_schema.Page{}, o.WithDeleted, *modelsInUse)
+	if err != nil {
+		return nil, fmt.Errorf("could not build select: %s", err)
+	}
+
+	queryData, err := o.runBuilt(tx, dataSource, built, true, ctx, dependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	if transformed, err := o.transformResults(
+		queryData.Results, model.Name, model, o.Project, o.Populate, modelsInUse, ctx,
+	); err != nil {
+		return nil, err
+	} else {
+		queryData.Results = &transformed
+	}
+	return queryData, nil
+}
+
+func (o *orm) runBuilt(
+	tx *sql.Tx,
+	dataSource *DataSource,
+	built *orm_schema.BuiltQuery,
+	scan bool,
+	ctx context.Context,
+	dependencies map[common.IntIota]any,
+) (*queryData, error) {
+	q := query{QueryString: built.QueryString, Scan: scan}
+	return q.init(tx, dataSource, &queryParameters{positional: built.Parameters}, ctx, dependencies)
+}
+
+func (o *orm) execBuilt(
+	tx *sql.Tx,
+	dataSource *DataSource,
+	built *orm_schema.BuiltQuery,
+	ctx context.Context,
+	dependencies map[common.IntIota]any,
+) (int, error) {
+	queryData, err := o.runBuilt(tx, dataSource, built, false, ctx, dependencies)
+	if err != nil {
+		return 0, err
+	}
+	return queryData.Metadata.RowsAffected, nil
+}
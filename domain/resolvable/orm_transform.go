@@ -0,0 +1,178 @@
+package resolvable
+
+import (
+	"context"
+	"fmt"
+	"ifttt/handler/common"
+	"ifttt/handler/domain/orm_schema"
+)
+
+type ormTransformPlan struct {
+	alias       string
+	model       string
+	pkAccessor  string
+	projections []orm_schema.Projection
+	children    []ormTransformChild
+}
+
+type ormTransformChild struct {
+	as     string
+	single bool
+	plan   *ormTransformPlan
+}
+
+type ormTransformGroup struct {
+	order []*ormTransformNode
+	nodes map[any]*ormTransformNode
+}
+
+type ormTransformNode struct {
+	row      map[string]any
+	children []ormTransformGroup
+}
+
+func (o *orm) transformResults(
+	rawResults *[]map[string]any,
+	alias string,
+	currModel *orm_schema.Model,
+	customProjections *[]orm_schema.Projection,
+	populate *[]orm_schema.Populate,
+	modelsInUse *map[string]*orm_schema.Model,
+	ctx context.Context,
+) ([]map[string]any, error) {
+	if rawResults == nil {
+		return nil, nil
+	}
+
+	plan, err := newOrmTransformPlan(alias, currModel, customProjections, populate, modelsInUse)
+	if err != nil {
+		return nil, err
+	}
+
+	root := ormTransformGroup{}
+	for idx, row := range *rawResults {
+		if idx%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		if err := root.add(plan, row); err != nil {
+			return nil, err
+		}
+	}
+	return root.flatten(plan), nil
+}
+
+func newOrmTransformPlan(
+	alias string,
+	model *orm_schema.Model,
+	customProjections *[]orm_schema.Projection,
+	populate *[]orm_schema.Populate,
+	modelsInUse *map[string]*orm_schema.Model,
+) (*ormTransformPlan, error) {
+	if model.PrimaryKey == "" {
+		return nil, fmt.Errorf("primary key not found in model %s", model.Name)
+	}
+
+	plan := ormTransformPlan{
+		alias:       alias,
+		model:       model.Name,
+		pkAccessor:  fmt.Sprintf("%s.%s", alias, model.PrimaryKey),
+		projections: model.Projections,
+	}
+	if customProjections != nil && len(*customProjections) > 0 {
+		plan.projections = *customProjections
+	}
+	if populate == nil {
+		return &plan, nil
+	}
+
+	for _, p := range *populate {
+		childModel, ok := (*modelsInUse)[p.Model]
+		if !ok || childModel == nil {
+			return nil, fmt.Errorf("model %s not found", p.Model)
+		}
+		association, err := model.ResolveAssociation(childModel, p.Association)
+		if err != nil {
+			return nil, err
+		}
+
+		childAlias := fmt.Sprintf("%s_%s", alias, p.As)
+		childPlan, err := newOrmTransformPlan(childAlias, childModel, &p.Project, &p.Populate, modelsInUse)
+		if err != nil {
+			return nil, fmt.Errorf("could not transform alias %s for model %s: %s", childAlias, p.Model, err)
+		}
+		plan.children = append(plan.children, ormTransformChild{
+			as: p.As,
+			single: association.Type == common.AssociationsHasOne ||
+				association.Type == common.AssociationsBelongsTo,
+			plan: childPlan,
+		})
+	}
+	return &plan, nil
+}
+
+func (g *ormTransformGroup) add(plan *ormTransformPlan, row map[string]any) error {
+	key := row[plan.pkAccessor]
+	if key == nil {
+		return nil
+	}
+
+	node, ok := g.nodes[key]
+	if !ok {
+		projected, err := projectRow(row, plan.projections, plan.alias)
+		if err != nil {
+			return fmt.Errorf("could not transform alias %s for model %s: %s", plan.alias, plan.model, err)
+		}
+		node = &ormTransformNode{row: projected}
+		if len(plan.children) > 0 {
+			node.children = make([]ormTransformGroup, len(plan.children))
+		}
+		if g.nodes == nil {
+			g.nodes = map[any]*ormTransformNode{}
+		}
+		g.nodes[key] = node
+		g.order = append(g.order, node)
+	}
+
+	for idx := range plan.children {
+		if err := node.children[idx].add(plan.children[idx].plan, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *ormTransformGroup) flatten(plan *ormTransformPlan) []map[string]any {
+	flattened := make([]map[string]any, len(g.order))
+	for idx, node := range g.order {
+		for childIdx, child := range plan.children {
+			rows := node.children[childIdx].flatten(child.plan)
+			if !child.single {
+				node.row[child.as] = rows
+			} else if len(rows) > 0 {
+				node.row[child.as] = rows[0]
+			} else {
+				node.row[child.as] = nil
+			}
+		}
+		flattened[idx] = node.row
+	}
+	return flattened
+}
+
+func projectRow(row map[string]any, projections []orm_schema.Projection, alias string) (map[string]any, error) {
+	projectedRow := make(map[string]any, len(projections))
+	prefix := alias + "."
+
+	for idx := range projections {
+		p := &projections[idx]
+		colVal, exists := row[prefix+p.Column]
+		if sanitized, err := p.SanitizeValue(colVal, exists); err != nil {
+			return nil, err
+		} else {
+			projectedRow[p.As] = sanitized
+		}
+	}
+	return projectedRow, nil
+}
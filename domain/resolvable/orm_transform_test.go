@@ -0,0 +1,226 @@
+package resolvable
+
+import (
+	"context"
+	"fmt"
+	"ifttt/handler/common"
+	"ifttt/handler/domain/orm_schema"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/samber/lo"
+)
+
+// referenceTransformResults is the goroutine per group transformer that
+// transformResults replaced, kept to compare output and performance
+func referenceTransformResults(
+	rawResults *[]map[string]any,
+	alias string,
+	currModel *orm_schema.Model,
+	customProjections *[]orm_schema.Projection,
+	populate *[]orm_schema.Populate,
+	modelsInUse *map[string]*orm_schema.Model,
+	ctx context.Context,
+) ([]map[string]any, error) {
+	if rawResults == nil {
+		return nil, nil
+	} else if currModel.PrimaryKey == "" {
+		return nil, fmt.Errorf("primary key not found in model %s", currModel.Name)
+	}
+
+	pKeyAccessor := fmt.Sprintf("%s.%s", alias, currModel.PrimaryKey)
+	pKeyOrder := lo.Uniq(lo.FilterMap(*rawResults, func(row map[string]any, _ int) (any, bool) {
+		return row[pKeyAccessor], row[pKeyAccessor] != nil
+	}))
+	grouped := lo.GroupBy(*rawResults, func(row map[string]any) any {
+		return row[pKeyAccessor]
+	})
+	delete(grouped, nil)
+
+	flattened := make([]map[string]any, len(pKeyOrder))
+	wg := sync.WaitGroup{}
+	cancelCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	for idx, key := range pKeyOrder {
+		rowGroup, ok := grouped[key]
+		if !ok {
+			return nil, fmt.Errorf("group for key %s not found", key)
+		}
+
+		wg.Add(1)
+		go func(idx int, rowGroup []map[string]any) {
+			defer wg.Done()
+			select {
+			case <-cancelCtx.Done():
+				return
+			default:
+			}
+
+			projections := currModel.Projections
+			if customProjections != nil && len(*customProjections) > 0 {
+				projections = *customProjections
+			}
+			transformedRow, err := projectRow(rowGroup[0], projections, alias)
+			if err != nil {
+				cancel(err)
+				return
+			}
+			if populate != nil {
+				for _, p := range *populate {
+					childModel, ok := (*modelsInUse)[p.Model]
+					if !ok || childModel == nil {
+						cancel(fmt.Errorf("model %s not found", p.Model))
+						return
+					}
+					association, err := currModel.ResolveAssociation(childModel, p.Association)
+					if err != nil {
+						cancel(err)
+						return
+					}
+					childAlias := fmt.Sprintf("%s_%s", alias, p.As)
+					childGroups, err := referenceTransformResults(
+						&rowGroup, childAlias, childModel, &p.Project, &p.Populate, modelsInUse, ctx)
+					if err != nil {
+						cancel(err)
+						return
+					}
+					if association.Type == common.AssociationsHasOne ||
+						association.Type == common.AssociationsBelongsTo {
+						if len(childGroups) > 0 {
+							transformedRow[p.As] = childGroups[0]
+						} else {
+							transformedRow[p.As] = nil
+						}
+					} else {
+						transformedRow[p.As] = childGroups
+					}
+				}
+			}
+			flattened[idx] = transformedRow
+		}(idx, rowGroup)
+	}
+	wg.Wait()
+	if err := context.Cause(cancelCtx); err != nil {
+		return nil, err
+	}
+	return flattened, nil
+}
+
+type transformFixture struct {
+	rows     []map[string]any
+	model    *orm_schema.Model
+	populate []orm_schema.Populate
+	models   map[string]*orm_schema.Model
+}
+
+func newTransformFixture(users, postsPerUser, commentsPerPost int) *transformFixture {
+	projections := func(columns ...string) []orm_schema.Projection {
+		return lo.Map(columns, func(c string, _ int) orm_schema.Projection {
+			modelType := common.DatabaseTypeString
+			if c == "id" {
+				modelType = common.DatabaseTypeNumber
+			}
+			return orm_schema.Projection{Column: c, As: c, ModelType: modelType, SchemaType: modelType}
+		})
+	}
+	user := &orm_schema.Model{Name: "user", Table: "users", PrimaryKey: "id", Projections: projections("id", "name")}
+	post := &orm_schema.Model{Name: "post", Table: "posts", PrimaryKey: "id", Projections: projections("id", "title")}
+	comment := &orm_schema.Model{Name: "comment", Table: "comments", PrimaryKey: "id", Projections: projections("id", "body")}
+
+	userPosts := orm_schema.ModelAssociation{
+		Name: "posts", Type: common.AssociationsHasMany, TableName: "users", ColumnName: "id",
+		ReferencesTable: "posts", ReferencesField: "user_id", OwningModel: *user, ReferencesModel: *post,
+	}
+	postComments := orm_schema.ModelAssociation{
+		Name: "comments", Type: common.AssociationsHasMany, TableName: "posts", ColumnName: "id",
+		ReferencesTable: "comments", ReferencesField: "post_id", OwningModel: *post, ReferencesModel: *comment,
+	}
+	user.OwningAssociations = []orm_schema.ModelAssociation{userPosts}
+	post.ReferencedAssociations = []orm_schema.ModelAssociation{userPosts}
+	post.OwningAssociations = []orm_schema.ModelAssociation{postComments}
+	comment.ReferencedAssociations = []orm_schema.ModelAssociation{postComments}
+
+	rows := []map[string]any{}
+	postID, commentID := 0, 0
+	for u := users; u > 0; u-- {
+		if u%10 == 0 {
+			rows = append(rows, map[string]any{
+				"user.id": float64(u), "user.name": fmt.Sprintf("user %d", u),
+				"user_posts.id": nil, "user_posts.title": nil,
+				"user_posts_comments.id": nil, "user_posts_comments.body": nil,
+			})
+			continue
+		}
+		for p := 0; p < postsPerUser; p++ {
+			postID++
+			for c := 0; c < commentsPerPost; c++ {
+				commentID++
+				rows = append(rows, map[string]any{
+					"user.id": float64(u), "user.name": fmt.Sprintf("user %d", u),
+					"user_posts.id": float64(postID), "user_posts.title": fmt.Sprintf("post %d", postID),
+					"user_posts_comments.id":   float64(commentID),
+					"user_posts_comments.body": fmt.Sprintf("comment %d", commentID),
+				})
+			}
+		}
+	}
+
+	return &transformFixture{
+		rows:  rows,
+		model: user,
+		populate: []orm_schema.Populate{{
+			Model: "post", As: "posts", Association: "posts",
+			Populate: []orm_schema.Populate{{Model: "comment", As: "comments", Association: "comments"}},
+		}},
+		models: map[string]*orm_schema.Model{"user": user, "post": post, "comment": comment},
+	}
+}
+
+func (f *transformFixture) transform(reference bool) ([]map[string]any, error) {
+	project := []orm_schema.Projection{}
+	if reference {
+		return referenceTransformResults(&f.rows, f.model.Name, f.model, &project, &f.populate, &f.models,
+			context.Background())
+	}
+	return (&orm{}).transformResults(&f.rows, f.model.Name, f.model, &project, &f.populate, &f.models,
+		context.Background())
+}
+
+func TestTransformResultsMatchesReference(t *testing.T) {
+	fixture := newTransformFixture(500, 4, 2)
+
+	expected, err := fixture.transform(true)
+	if err != nil {
+		t.Fatalf("reference transform failed: %s", err)
+	}
+	actual, err := fixture.transform(false)
+	if err != nil {
+		t.Fatalf("transform failed: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("transform output differs from reference")
+	}
+	if len(actual) != 500 || actual[0]["id"] != float64(500) || actual[len(actual)-1]["id"] != float64(1) {
+		t.Fatalf("transform did not preserve row order")
+	}
+}
+
+func BenchmarkTransformResults(b *testing.B) {
+	fixture := newTransformFixture(1000, 4, 2)
+	for _, bench := range []struct {
+		name      string
+		reference bool
+	}{{"reference", true}, {"singlePass", false}} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := fixture.transform(bench.reference); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
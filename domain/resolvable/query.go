@@ -15,17 +15,32 @@ import (
 )
 
 type query struct {
-	QueryString string       `json:"queryString" mapstructure:"queryString"`
-	Scan        bool         `json:"scan" mapstructure:"scan"`
-	Parameters  []Resolvable `json:"parameters" mapstructure:"parameters"`
-	Async       bool         `json:"async" mapstructure:"async"`
-	Timeout     uint         `json:"timeout" mapstructure:"timeout"`
+	QueryString     string                `json:"queryString" mapstructure:"queryString"`
+	Scan            bool                  `json:"scan" mapstructure:"scan"`
+	Parameters      []Resolvable          `json:"parameters" mapstructure:"parameters"`
+	NamedParameters map[string]Resolvable `json:"namedParameters" mapstructure:"namedParameters"`
+	Async           bool                  `json:"async" mapstructure:"async"`
+	Handle          string                `json:"handle" mapstructure:"handle"`
+	Timeout         uint                  `json:"timeout" mapstructure:"timeout"`
+	DataSource      string                `json:"dataSource" mapstructure:"dataSource"`
+	Paginate        *queryPagination      `json:"paginate" mapstructure:"paginate"`
+	Stream          *queryStream          `json:"stream" mapstructure:"stream"`
+}
+
+type queryParameters struct {
+	positional []any
+	named      map[string]any
 }
 
 type queryData struct {
 	Request  *queryRequest     `json:"queryRequest" mapstructure:"queryRequest"`
 	Metadata *queryMetadata    `json:"queryMetadata" mapstructure:"queryMetadata"`
 	Results  *[]map[string]any `json:"results" mapstructure:"results"`
+	Page     *queryPage        `json:"page,omitempty" mapstructure:"page,omitempty"`
+
+	pagination   *queryPagination
+	stream       *queryStream
+	dependencies map[common.IntIota]any
 }
 
 type queryRequest struct {
@@ -41,67 +56,170 @@ type queryMetadata struct {
 	Timeout      uint      `json:"timeOut" mapstructure:"timeOut"`
 	DidTimeout   bool      `json:"didTimeout" mapstructure:"didTimeout"`
 	Async        bool      `json:"async" mapstructure:"async"`
+	DataSource   string    `json:"dataSource" mapstructure:"dataSource"`
 	Error        string    `json:"error" mapstructure:"error"`
 	RowsAffected int       `json:"rowsAffected" mapstructure:"rowsAffected"`
+	RowsMatched  int64     `json:"rowsMatched" mapstructure:"rowsMatched"`
 }
 
 type RawQueryRepository interface {
-	BeginTx(ctx context.Context) (*sql.Tx, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
 	Scan(tx *sql.Tx, queryString string, parameters []any, ctx context.Context) (*[]map[string]any, int, error)
 	Exec(tx *sql.Tx, queryString string, parameters []any, ctx context.Context) (int, error)
+	Stream(tx *sql.Tx, queryString string, parameters []any, ctx context.Context, fn func(row map[string]any) error) (int, error)
+	Dialect() string
 }
 
 func (q *query) Resolve(ctx context.Context, dependencies map[common.IntIota]any) (any, error) {
-	resolved, err := resolveArrayMustParallel(&q.Parameters, ctx, dependencies)
+	if err := q.validate(ctx); err != nil {
+		return nil, fmt.Errorf("invalid query: %s", err)
+	}
+
+	resolved, err := q.resolveParameters(ctx, dependencies)
 	if err != nil {
 		return nil, fmt.Errorf("could resolve parameters for query: %s", err)
 	}
 
-	rawQueryRepo, ok := dependencies[common.DependencyRawQueryRepo].(RawQueryRepository)
-	if !ok {
-		return nil, fmt.Errorf("method *QueryResolvable: could not cast raw query repo")
+	dataSource, err := getDataSource(q.DataSource, dependencies)
+	if err != nil {
+		return nil, fmt.Errorf("method *QueryResolvable: %s", err)
 	}
 
-	tx, err := rawQueryRepo.BeginTx(ctx)
+	queryData, err := q.prepare(dataSource, resolved, ctx, dependencies)
 	if err != nil {
-		return nil, fmt.Errorf("could not begin tx: %s", err)
+		return nil, err
 	}
 
-	queryData, err := q.init(tx, resolved, ctx, dependencies)
-	if err != nil {
-		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			return nil, fmt.Errorf("attempted rollback on error: %s. rollback failed %s", err, rollbackErr)
+	if q.Async {
+		if getTransactionScope(ctx) != nil {
+			return nil, fmt.Errorf("async queries cannot run inside a transaction")
+		}
+		handle, err := registerAsyncHandle(q.Handle, ctx)
+		if err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("rolled back. error: %s", err)
-	} else if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("commit failed: %s", err)
+		go func() {
+			if err := queryData.runInTx(dataSource, ctx); err != nil {
+				handle.Complete(nil, err)
+			} else {
+				handle.Complete(queryData, nil)
+			}
+		}()
+		return asyncHandleDescriptor(q.Handle), nil
 	}
 
+	if err := queryData.runInTx(dataSource, ctx); err != nil {
+		return nil, err
+	}
 	return queryData, nil
 }
 
-func (q *query) init(tx *sql.Tx, parameters []any, ctx context.Context, dependencies map[common.IntIota]any) (*queryData, error) {
-	queryData, err := q.createQueryData(parameters)
+func (q *query) validate(ctx context.Context) error {
+	if q.Paginate != nil && q.Stream != nil {
+		return fmt.Errorf("paginate and stream cannot be used together")
+	} else if (q.Paginate != nil || q.Stream != nil) && !q.Scan {
+		return fmt.Errorf("paginate and stream require scan")
+	} else if q.Stream != nil && getTransactionScope(ctx) != nil {
+		return fmt.Errorf("stream cannot run inside a transaction")
+	} else if q.Paginate != nil {
+		return q.Paginate.validate()
+	}
+	return nil
+}
+
+func (q *query) resolveParameters(ctx context.Context, dependencies map[common.IntIota]any) (*queryParameters, error) {
+	if len(q.NamedParameters) > 0 {
+		if len(q.Parameters) > 0 {
+			return nil, fmt.Errorf("positional and named parameters cannot be mixed")
+		}
+		named, err := resolveMapMustParallel(&q.NamedParameters, ctx, dependencies)
+		if err != nil {
+			return nil, err
+		}
+		return &queryParameters{named: named}, nil
+	}
+
+	positional, err := resolveArrayMustParallel(&q.Parameters, ctx, dependencies)
 	if err != nil {
-		return nil, fmt.Errorf("queryResolvable: could not create query data: %s", err)
+		return nil, err
 	}
+	return &queryParameters{positional: positional}, nil
+}
 
-	if q.Async {
-		go queryData.execute(tx, dependencies, ctx)
-	} else if err := queryData.execute(tx, dependencies, ctx); err != nil {
+func (q *queryData) runInTx(dataSource *DataSource, ctx context.Context) error {
+	txHandle, err := acquireTx(dataSource, ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = q.execute(txHandle.tx, dataSource.RawQueryRepo, ctx); err != nil {
+		err = fmt.Errorf("queryResolvable: could not execute query: %s", err)
+	}
+	return txHandle.finish(err)
+}
+
+func (q *query) init(
+	tx *sql.Tx, dataSource *DataSource, parameters *queryParameters,
+	ctx context.Context, dependencies map[common.IntIota]any,
+) (*queryData, error) {
+	queryData, err := q.prepare(dataSource, parameters, ctx, dependencies)
+	if err != nil {
+		return nil, err
+	}
+	if err := queryData.execute(tx, dataSource.RawQueryRepo, ctx); err != nil {
 		return nil, fmt.Errorf("queryResolvable: could not execute query: %s", err)
 	}
+	return queryData, nil
+}
+
+func (q *query) prepare(
+	dataSource *DataSource, parameters *queryParameters,
+	ctx context.Context, dependencies map[common.IntIota]any,
+) (*queryData, error) {
+	rawQueryRepo := dataSource.RawQueryRepo
+	queryData, err := q.createQueryData(parameters, rawQueryRepo.Dialect())
+	if err != nil {
+		return nil, fmt.Errorf("queryResolvable: could not create query data: %s", err)
+	}
+	queryData.Metadata.DataSource = dataSource.Name
+	if queryData.Metadata.Timeout == 0 {
+		queryData.Metadata.Timeout = dataSource.Timeout
+	}
+
+	if q.Paginate != nil {
+		cursor, err := resolveMaybe(q.Paginate.Cursor, ctx, dependencies)
+		if err != nil {
+			return nil, fmt.Errorf("queryResolvable: could not resolve cursor: %s", err)
+		}
+		cursorStr := ""
+		if cursor != nil {
+			cursorStr = fmt.Sprint(cursor)
+		}
+		if err := queryData.Request.applyPagination(q.Paginate, cursorStr, rawQueryRepo.Dialect()); err != nil {
+			return nil, fmt.Errorf("queryResolvable: could not paginate query: %s", err)
+		}
+		queryData.pagination = q.Paginate
+	} else if q.Stream != nil {
+		queryData.stream = q.Stream
+		queryData.dependencies = dependencies
+	}
 
 	return queryData, nil
 }
 
-func (q *query) createQueryData(parameters []any) (*queryData, error) {
+func (q *query) createQueryData(parameters *queryParameters, dialect string) (*queryData, error) {
 	req := queryRequest{
 		Scan:        q.Scan,
 		QueryString: q.QueryString,
 		Parameters:  []any{},
 	}
-	req.preProcess(parameters)
+	if parameters.named != nil {
+		if err := req.preProcessNamed(parameters.named, dialect); err != nil {
+			return nil, err
+		}
+	} else {
+		req.preProcess(parameters.positional, dialect)
+	}
 
 	queryData := queryData{
 		Request:  &req,
@@ -115,21 +233,20 @@ func (q *query) createQueryMetadata() *queryMetadata {
 	return &queryMetadata{Timeout: q.Timeout, Async: q.Async}
 }
 
-func (q *queryData) execute(tx *sql.Tx, dependencies map[common.IntIota]any, ctx context.Context) error {
+func (q *queryData) execute(tx *sql.Tx, rawQueryRepo RawQueryRepository, ctx context.Context) error {
 	defer func() {
+		identifier := q.Request.QueryString
+		if len(identifier) > 20 {
+			identifier = identifier[20:]
+		}
 		mapped := structs.Map(q)
 		request_data.AddExternalTrip(common.ExternalTripQuery,
-			q.Request.QueryString[20:],
+			identifier,
 			&mapped, q.Metadata.TimeTaken, ctx)
 	}()
 
 	q.Metadata.Start = time.Now()
 
-	rawQueryRepo, ok := dependencies[common.DependencyRawQueryRepo].(RawQueryRepository)
-	if !ok {
-		return fmt.Errorf("method *QueryResolvable: could not cast raw query repo")
-	}
-
 	var (
 		results      *[]map[string]any
 		rowsAffected int
@@ -138,9 +255,9 @@ func (q *queryData) execute(tx *sql.Tx, dependencies map[common.IntIota]any, ctx
 	if q.Metadata.Timeout > 0 {
 		timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(q.Metadata.Timeout)*time.Millisecond)
 		defer cancel()
-		results, rowsAffected, err = q.Request.runQuery(tx, rawQueryRepo, timeoutCtx)
+		results, rowsAffected, err = q.run(tx, rawQueryRepo, timeoutCtx)
 	} else {
-		results, rowsAffected, err = q.Request.runQuery(tx, rawQueryRepo, ctx)
+		results, rowsAffected, err = q.run(tx, rawQueryRepo, ctx)
 	}
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
@@ -153,6 +270,13 @@ func (q *queryData) execute(tx *sql.Tx, dependencies map[common.IntIota]any, ctx
 		return nil
 	}
 
+	if q.pagination != nil {
+		if results, q.Page, err = q.pagination.page(results); err != nil {
+			q.Metadata.Error = err.Error()
+			return err
+		}
+	}
+
 	q.Results = results
 	q.Metadata.RowsAffected = rowsAffected
 	q.Metadata.End = time.Now()
@@ -161,7 +285,17 @@ func (q *queryData) execute(tx *sql.Tx, dependencies map[common.IntIota]any, ctx
 	return nil
 }
 
-func (q *queryRequest) preProcess(parameters []any) {
+func (q *queryData) run(tx *sql.Tx, rawQueryRepo RawQueryRepository, ctx context.Context) (*[]map[string]any, int, error) {
+	if q.stream != nil {
+		rowHandler, cancel := q.stream.rowHandler(&q.Metadata.RowsMatched, ctx, q.dependencies)
+		defer cancel(nil)
+		rowsAffected, err := rawQueryRepo.Stream(tx, q.Request.QueryString, q.Request.Parameters, ctx, rowHandler)
+		return nil, rowsAffected, err
+	}
+	return q.Request.runQuery(tx, rawQueryRepo, ctx)
+}
+
+func (q *queryRequest) preProcess(parameters []any, dialect string) {
 	var builder strings.Builder
 	builder.Grow(len(q.QueryString))
 
@@ -173,46 +307,88 @@ func (q *queryRequest) preProcess(parameters []any) {
 		idx              int
 	)
 
-	for _, v := range q.QueryString {
-		if v == '?' && idx < len(parameters) {
-			if afterParenthesis {
-				rv := reflect.ValueOf(parameters[idx])
-				switch rv.Kind() {
-				case reflect.Slice, reflect.Array:
-					if rv.Len() == 0 {
-						builder.WriteRune('?')
-						q.Parameters = append(q.Parameters, nil)
-					} else {
-						if rv.Len() > 1 {
-							builder.WriteRune('?')
-							for i := 1; i < rv.Len(); i++ {
-								builder.WriteString(",?")
-							}
-						} else {
-							builder.WriteRune('?')
-						}
-						for i := 0; i < rv.Len(); i++ {
-							q.Parameters = append(q.Parameters, rv.Index(i).Interface())
-						}
-					}
-				default:
-					builder.WriteRune('?')
-					q.Parameters = append(q.Parameters, parameters[idx])
-				}
+	for _, span := range scanSQL(q.QueryString, dialect) {
+		segment := q.QueryString[span.start:span.end]
+		if !span.code {
+			afterParenthesis = false
+			builder.WriteString(segment)
+			continue
+		}
+		for _, v := range segment {
+			if v == '?' && idx < len(parameters) {
+				q.appendParameter(&builder, parameters[idx], afterParenthesis, dialect)
+				idx++
 			} else {
-				builder.WriteRune('?')
-				q.Parameters = append(q.Parameters, parameters[idx])
+				afterParenthesis = v == '('
+				builder.WriteRune(v)
 			}
-			idx++
-		} else {
-			afterParenthesis = v == '('
-			builder.WriteRune(v)
 		}
 	}
 
 	q.QueryString = builder.String()
 }
 
+func (q *queryRequest) preProcessNamed(parameters map[string]any, dialect string) error {
+	var builder strings.Builder
+	builder.Grow(len(q.QueryString))
+	q.Parameters = []any{}
+
+	for _, span := range scanSQL(q.QueryString, dialect) {
+		segment := q.QueryString[span.start:span.end]
+		if !span.code {
+			builder.WriteString(segment)
+			continue
+		}
+
+		last := 0
+		for _, match := range common.RegexNamedParameters.FindAllStringIndex(segment, -1) {
+			preceding := segment[last:match[0]]
+			builder.WriteString(preceding)
+			last = match[1]
+
+			token := segment[match[0]:match[1]]
+			if match[0] > 0 && segment[match[0]-1] == '@' {
+				builder.WriteString(token)
+				continue
+			}
+
+			value, ok := parameters[token[1:]]
+			if !ok {
+				return fmt.Errorf("named parameter %s not provided", token)
+			}
+			afterParenthesis := strings.HasSuffix(strings.TrimRight(preceding, " \t\n"), "(")
+			q.appendParameter(&builder, value, afterParenthesis, dialect)
+		}
+		builder.WriteString(segment[last:])
+	}
+
+	q.QueryString = builder.String()
+	return nil
+}
+
+func (q *queryRequest) appendParameter(builder *strings.Builder, parameter any, expand bool, dialect string) {
+	rv := reflect.ValueOf(parameter)
+	if !expand || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+		q.Parameters = append(q.Parameters, parameter)
+		builder.WriteString(common.SqlPlaceholder(dialect, len(q.Parameters)))
+		return
+	}
+
+	if rv.Len() == 0 {
+		q.Parameters = append(q.Parameters, nil)
+		builder.WriteString(common.SqlPlaceholder(dialect, len(q.Parameters)))
+		return
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		if i > 0 {
+			builder.WriteRune(',')
+		}
+		q.Parameters = append(q.Parameters, rv.Index(i).Interface())
+		builder.WriteString(common.SqlPlaceholder(dialect, len(q.Parameters)))
+	}
+}
+
 func (q *queryRequest) runQuery(tx *sql.Tx, rawQueryRepo RawQueryRepository, ctx context.Context) (*[]map[string]any, int, error) {
 	var (
 		results      *[]map[string]any
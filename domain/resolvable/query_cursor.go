@@ -0,0 +1,177 @@
+package resolvable
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"ifttt/handler/common"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+type queryPagination struct {
+	Keys   []paginationKey `json:"keys" mapstructure:"keys"`
+	Limit  uint            `json:"limit" mapstructure:"limit"`
+	Cursor any             `json:"cursor" mapstructure:"cursor"`
+}
+
+type paginationKey struct {
+	Column    string `json:"column" mapstructure:"column"`
+	Direction string `json:"direction" mapstructure:"direction"`
+}
+
+type queryPage struct {
+	NextCursor string `json:"nextCursor" mapstructure:"nextCursor"`
+	HasMore    bool   `json:"hasMore" mapstructure:"hasMore"`
+	Total      any    `json:"total,omitempty" mapstructure:"total,omitempty"`
+}
+
+type queryStream struct {
+	Condition Condition    `json:"condition" mapstructure:"condition"`
+	Do        []Resolvable `json:"do" mapstructure:"do"`
+}
+
+func (p *queryPagination) validate() error {
+	if p.Limit == 0 {
+		return fmt.Errorf("pagination limit is required")
+	} else if len(p.Keys) == 0 {
+		return fmt.Errorf("pagination keys are required")
+	}
+	for idx, k := range p.Keys {
+		if !common.RegexSqlIdentifier.MatchString(k.Column) {
+			return fmt.Errorf("invalid pagination column %s", k.Column)
+		}
+		switch strings.ToLower(k.Direction) {
+		case "":
+			p.Keys[idx].Direction = common.SortAscending
+		case common.SortAscending, common.SortDescending:
+			p.Keys[idx].Direction = strings.ToLower(k.Direction)
+		default:
+			return fmt.Errorf("invalid pagination direction %s", k.Direction)
+		}
+	}
+	return nil
+}
+
+func (q *queryRequest) applyPagination(p *queryPagination, cursor string, dialect string) error {
+	var builder strings.Builder
+	builder.WriteString("SELECT * FROM (")
+	builder.WriteString(strings.TrimRight(strings.TrimSpace(q.QueryString), ";"))
+	builder.WriteString(") AS paginated")
+
+	if cursor != "" {
+		values, err := decodeCursor(cursor, len(p.Keys))
+		if err != nil {
+			return err
+		}
+		builder.WriteString(" WHERE ")
+		builder.WriteString(keysetCondition(p.Keys, values, dialect, &q.Parameters))
+	}
+
+	orderBy := make([]string, 0, len(p.Keys))
+	for _, k := range p.Keys {
+		orderBy = append(orderBy,
+			fmt.Sprintf("%s %s", common.SqlQuoteIdentifier(dialect, k.Column), strings.ToUpper(k.Direction)))
+	}
+	builder.WriteString(" ORDER BY ")
+	builder.WriteString(strings.Join(orderBy, ", "))
+	builder.WriteString(fmt.Sprintf(" LIMIT %d", p.Limit+1))
+
+	q.QueryString = builder.String()
+	return nil
+}
+
+func (p *queryPagination) page(results *[]map[string]any) (*[]map[string]any, *queryPage, error) {
+	page := queryPage{}
+	if results == nil || len(*results) <= int(p.Limit) {
+		return results, &page, nil
+	}
+
+	trimmed := (*results)[:p.Limit]
+	last := trimmed[len(trimmed)-1]
+	values := make([]any, 0, len(p.Keys))
+	for _, k := range p.Keys {
+		values = append(values, last[k.Column])
+	}
+	cursor, err := encodeCursor(values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page.HasMore = true
+	page.NextCursor = cursor
+	return &trimmed, &page, nil
+}
+
+func keysetCondition(
+	keys []paginationKey, values []any, dialect string, parameters *[]any,
+) string {
+	columns := make([]string, len(keys))
+	descending := make([]bool, len(keys))
+	for idx, k := range keys {
+		columns[idx] = common.SqlQuoteIdentifier(dialect, k.Column)
+		descending[idx] = k.Direction == common.SortDescending
+	}
+	return common.SqlKeysetCondition(columns, descending, values, func(value any) string {
+		*parameters = append(*parameters, value)
+		return common.SqlPlaceholder(dialect, len(*parameters))
+	})
+}
+
+func encodeCursor(values []any) (string, error) {
+	marshalled, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("could not encode cursor: %s", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(marshalled), nil
+}
+
+func decodeCursor(cursor string, length int) ([]any, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %s", err)
+	}
+	var values []any
+	decoder := json.NewDecoder(bytes.NewReader(decoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %s", err)
+	} else if len(values) != length {
+		return nil, fmt.Errorf("invalid cursor: expected %d values, got %d", length, len(values))
+	}
+	for idx, value := range values {
+		if number, ok := value.(json.Number); ok {
+			if integer, err := number.Int64(); err == nil {
+				values[idx] = integer
+			} else if unsigned, err := strconv.ParseUint(number.String(), 10, 64); err == nil {
+				values[idx] = unsigned
+			} else if float, err := number.Float64(); err == nil {
+				values[idx] = float
+			} else {
+				return nil, fmt.Errorf("invalid cursor: %s", err)
+			}
+		}
+	}
+	return values, nil
+}
+
+func (s *queryStream) rowHandler(
+	matched *int64, ctx context.Context, dependencies map[common.IntIota]any,
+) (func(row map[string]any) error, context.CancelCauseFunc) {
+	iteration := filterMap{Do: &s.Do, Condition: s.Condition}
+	cancelCtx, cancel := context.WithCancelCause(ctx)
+	index := 0
+	return func(row map[string]any) error {
+		element := iterElement{Element: row, Index: index}
+		index++
+		if _, ev, err := iteration.singleIteration(&element, cancelCtx, cancel, ctx, dependencies); err != nil {
+			return err
+		} else if ev {
+			atomic.AddInt64(matched, 1)
+		}
+		return nil
+	}, cancel
+}
@@ -0,0 +1,98 @@
+package resolvable
+
+import (
+	"ifttt/handler/common"
+	"strings"
+)
+
+type sqlSpan struct {
+	start int
+	end   int
+	code  bool
+}
+
+func scanSQL(query string, dialect string) []sqlSpan {
+	spans := []sqlSpan{}
+	start := 0
+	for idx := 0; idx < len(query); {
+		end := skipSQLLiteral(query, idx, dialect)
+		if end == idx {
+			idx++
+			continue
+		}
+		if idx > start {
+			spans = append(spans, sqlSpan{start: start, end: idx, code: true})
+		}
+		spans = append(spans, sqlSpan{start: idx, end: end})
+		idx, start = end, end
+	}
+	if start < len(query) {
+		spans = append(spans, sqlSpan{start: start, end: len(query), code: true})
+	}
+	return spans
+}
+
+func skipSQLLiteral(query string, idx int, dialect string) int {
+	switch c := query[idx]; {
+	case c == '\'':
+		escapes := dialect == common.DialectMySQL ||
+			(idx > 0 && (query[idx-1] == 'E' || query[idx-1] == 'e') && dialect == common.DialectPostgres)
+		return skipSQLQuoted(query, idx, '\'', escapes)
+	case c == '"':
+		return skipSQLQuoted(query, idx, '"', dialect == common.DialectMySQL)
+	case c == '`' && dialect != common.DialectPostgres:
+		return skipSQLQuoted(query, idx, '`', false)
+	case c == '-' && strings.HasPrefix(query[idx:], "--"),
+		c == '#' && dialect == common.DialectMySQL:
+		if end := strings.IndexByte(query[idx:], '\n'); end >= 0 {
+			return idx + end + 1
+		}
+		return len(query)
+	case c == '/' && strings.HasPrefix(query[idx:], "/*"):
+		if end := strings.Index(query[idx+2:], "*/"); end >= 0 {
+			return idx + 2 + end + 2
+		}
+		return len(query)
+	case c == '$' && dialect == common.DialectPostgres:
+		return skipDollarQuoted(query, idx)
+	}
+	return idx
+}
+
+func skipSQLQuoted(query string, idx int, quote byte, escapes bool) int {
+	for pos := idx + 1; pos < len(query); pos++ {
+		switch query[pos] {
+		case '\\':
+			if escapes {
+				pos++
+			}
+		case quote:
+			if pos+1 < len(query) && query[pos+1] == quote {
+				pos++
+				continue
+			}
+			return pos + 1
+		}
+	}
+	return len(query)
+}
+
+func skipDollarQuoted(query string, idx int) int {
+	tagEnd := strings.IndexByte(query[idx+1:], '$')
+	if tagEnd < 0 {
+		return idx
+	}
+	tag := query[idx : idx+1+tagEnd+1]
+	for _, r := range tag[1 : len(tag)-1] {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return idx
+		}
+	}
+	if tag != "$$" && tag[1] >= '0' && tag[1] <= '9' {
+		return idx
+	}
+	if end := strings.Index(query[idx+len(tag):], tag); end >= 0 {
+		return idx + len(tag) + end + len(tag)
+	}
+	return len(query)
+}
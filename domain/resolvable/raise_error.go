@@ -0,0 +1,59 @@
+package resolvable
+
+import (
+	"context"
+	"fmt"
+	"ifttt/handler/common"
+	"ifttt/handler/domain/request_data"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+type raiseError struct {
+	Event   any `json:"event" mapstructure:"event"`
+	Code    any `json:"code" mapstructure:"code"`
+	Message any `json:"message" mapstructure:"message"`
+	Data    any `json:"data" mapstructure:"data"`
+}
+
+func (r *raiseError) Resolve(ctx context.Context, dependencies map[common.IntIota]any) (any, error) {
+	resolvedEvent, err := resolveMaybe(r.Event, ctx, dependencies)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve event: %s", err)
+	}
+	event, ok := raisedEvent(resolvedEvent)
+	if !ok {
+		return nil, fmt.Errorf("event %v is not a valid event code", resolvedEvent)
+	}
+
+	userError := map[string]any{}
+	for key, val := range map[string]any{"code": r.Code, "message": r.Message, "data": r.Data} {
+		if userError[key], err = resolveMaybe(val, ctx, dependencies); err != nil {
+			return nil, fmt.Errorf("could not resolve %s: %s", key, err)
+		}
+	}
+
+	if err := request_data.GetRequestData(ctx).SetStore(common.InternalTagErrorUser, userError); err != nil {
+		return nil, err
+	}
+	common.GetCtxState(ctx).Store(common.ContextErrorRaised, true)
+	common.LogWithTracer(common.LogUser,
+		fmt.Sprintf("raising error with event %d", event), userError, false, ctx)
+
+	response := &Response{Event: event}
+	if _, err := response.Resolve(ctx, dependencies); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("error raised | code: %v | message: %v", userError["code"], userError["message"])
+}
+
+func raisedEvent(val any) (uint, bool) {
+	if val == nil {
+		return common.EventCodes[common.EventUserError], true
+	}
+	var event uint
+	if err := mapstructure.WeakDecode(val, &event); err != nil {
+		return 0, false
+	}
+	return event, true
+}
@@ -24,6 +24,7 @@ const (
 	accessorSetStore            = "setStore"
 	accessorSetLog              = "log"
 	accessorResponse            = "response"
+	accessorRaiseError          = "raiseError"
 	accessorStringInterpolation = "stringInterpolation"
 	accessorEncode              = "encode"
 	accessorSetCache            = "setCache"
@@ -38,6 +39,8 @@ const (
 	accessorDateFunc            = "dateFunc"
 	accessorDateIntervals       = "dateIntervals"
 	accessorConditional         = "conditional"
+	accessorAwait               = "await"
+	accessorTransaction         = "transaction"
 )
 
 type resolvableInterface interface {
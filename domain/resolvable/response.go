@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"ifttt/handler/common"
 	"ifttt/handler/domain/configuration"
+	"reflect"
+
+	"github.com/mitchellh/mapstructure"
 )
 
 type Response struct {
@@ -12,9 +15,29 @@ type Response struct {
 }
 
 type ResponseDefinition struct {
-	UseProfile     string         `json:"useProfile" mapstructure:"useProfile"`
-	Definition     map[string]any `json:"definition" mapstructure:"definition"`
-	HTTPStatusCode int            `json:"httpStatusCode" mapstructure:"httpStatusCode"`
+	UseProfile     string           `json:"useProfile" mapstructure:"useProfile"`
+	Definition     map[string]any   `json:"definition" mapstructure:"definition"`
+	HTTPStatusCode any              `json:"httpStatusCode" mapstructure:"httpStatusCode"`
+	Format         string           `json:"format" mapstructure:"format"`
+	ContentType    string           `json:"contentType" mapstructure:"contentType"`
+	Body           any              `json:"body" mapstructure:"body"`
+	Headers        map[string]any   `json:"headers" mapstructure:"headers"`
+	Cookies        []ResponseCookie `json:"cookies" mapstructure:"cookies"`
+	Redirect       any              `json:"redirect" mapstructure:"redirect"`
+	FileName       any              `json:"fileName" mapstructure:"fileName"`
+	XMLRoot        string           `json:"xmlRoot" mapstructure:"xmlRoot"`
+	CSVColumns     []string         `json:"csvColumns" mapstructure:"csvColumns"`
+}
+
+type ResponseCookie struct {
+	Name     string `json:"name" mapstructure:"name"`
+	Value    any    `json:"value" mapstructure:"value"`
+	Path     string `json:"path" mapstructure:"path"`
+	Domain   string `json:"domain" mapstructure:"domain"`
+	MaxAge   int    `json:"maxAge" mapstructure:"maxAge"`
+	Secure   bool   `json:"secure" mapstructure:"secure"`
+	HTTPOnly bool   `json:"httpOnly" mapstructure:"httpOnly"`
+	SameSite string `json:"sameSite" mapstructure:"sameSite"`
 }
 
 func (e *Response) Resolve(ctx context.Context, dependencies map[common.IntIota]any) (any, error) {
@@ -40,25 +63,81 @@ func (e *Response) ChannelSend(responseChannel chan Response, ctx context.Contex
 	}
 }
 
-func (e *Response) HandlerEvent(ctx context.Context, dependencies map[common.IntIota]any) (*map[string]any, int, error) {
-	apiProfilesUncasted, ok := common.GetCtxState(ctx).Load(common.ContextResponseProfiles)
-	if !ok {
-		return nil, 0, fmt.Errorf("no api profiles found")
+func (e *Response) HandlerEvent(ctx context.Context, dependencies map[common.IntIota]any) (*RenderedResponse, error) {
+	if apiProfilesUncasted, ok := common.GetCtxState(ctx).Load(common.ContextResponseProfiles); ok {
+		apiProfiles, ok := apiProfilesUncasted.(map[uint]ResponseDefinition)
+		if !ok {
+			return nil, fmt.Errorf("could not cast response profiles")
+		}
+		if responseDefinition, ok := apiProfiles[e.Event]; ok {
+			return responseDefinition.render(ctx, dependencies)
+		}
 	}
-	apiProfiles, ok := apiProfilesUncasted.(map[uint]ResponseDefinition)
-	if !ok {
-		return nil, 0, fmt.Errorf("could not cast response profiles")
+
+	if fallbacks, ok := dependencies[common.DependencyEventFallbacks].(map[uint]ResponseDefinition); ok {
+		if responseDefinition, ok := fallbacks[e.Event]; ok {
+			return responseDefinition.render(ctx, dependencies)
+		}
 	}
+	return nil, fmt.Errorf("response definition for event %d not found", e.Event)
+}
 
-	responseDefinition, ok := apiProfiles[e.Event]
-	if !ok {
-		return nil, 0, fmt.Errorf("response definition for event %d not found", e.Event)
+func EventFallbacks(events []configuration.Event) map[uint]ResponseDefinition {
+	fallbacks := make(map[uint]ResponseDefinition, len(events))
+	for _, event := range events {
+		fallbacks[event.Code] = ResponseDefinition{
+			Definition:     event.Body,
+			HTTPStatusCode: event.HTTPStatus,
+		}
 	}
+	return fallbacks
+}
 
-	responseScanned, err := configuration.ScanFromInternalTags(responseDefinition.Definition, ctx)
-	if err != nil {
-		return nil, 0, err
+func CollectResponseEvents(val any) []uint {
+	events := []uint{}
+	rv := reflect.Indirect(reflect.ValueOf(val))
+	if !rv.IsValid() {
+		return events
 	}
 
-	return responseScanned, responseDefinition.HTTPStatusCode, nil
+	switch rv.Kind() {
+	case reflect.Struct:
+		if r, ok := rv.Interface().(Resolvable); ok {
+			if r.ResolveType == accessorResponse {
+				var response Response
+				if err := mapstructure.WeakDecode(r.ResolveData, &response); err == nil {
+					events = append(events, response.Event)
+				}
+				return events
+			}
+			if r.ResolveType == accessorRaiseError {
+				if event, ok := raisedEvent(r.ResolveData["event"]); ok {
+					events = append(events, event)
+				}
+			}
+			return append(events, CollectResponseEvents(r.ResolveData)...)
+		}
+		for idx := 0; idx < rv.NumField(); idx++ {
+			if rv.Type().Field(idx).IsExported() {
+				events = append(events, CollectResponseEvents(rv.Field(idx).Interface())...)
+			}
+		}
+	case reflect.Map:
+		if m, ok := rv.Interface().(map[string]any); ok {
+			if _, ok := m["resolveType"].(string); ok {
+				var r Resolvable
+				if err := mapstructure.Decode(m, &r); err == nil {
+					return CollectResponseEvents(r)
+				}
+			}
+		}
+		for _, key := range rv.MapKeys() {
+			events = append(events, CollectResponseEvents(rv.MapIndex(key).Interface())...)
+		}
+	case reflect.Slice, reflect.Array:
+		for idx := 0; idx < rv.Len(); idx++ {
+			events = append(events, CollectResponseEvents(rv.Index(idx).Interface())...)
+		}
+	}
+	return events
 }
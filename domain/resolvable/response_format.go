@@ -0,0 +1,416 @@
+package resolvable
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"ifttt/handler/common"
+	"ifttt/handler/domain/configuration"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+const internalTagKey = "internalTag"
+
+type RenderedResponse struct {
+	StatusCode  int
+	ContentType string
+	Headers     map[string]string
+	Cookies     []RenderedCookie
+	Redirect    string
+	Body        []byte
+}
+
+type RenderedCookie struct {
+	ResponseCookie
+	Value string
+}
+
+var responseContentTypes = map[string]string{
+	common.ResponseFormatJSON:   "application/json",
+	common.ResponseFormatXML:    "application/xml",
+	common.ResponseFormatText:   "text/plain; charset=utf-8",
+	common.ResponseFormatCSV:    "text/csv; charset=utf-8",
+	common.ResponseFormatBinary: "application/octet-stream",
+}
+
+func (d *ResponseDefinition) render(
+	ctx context.Context, dependencies map[common.IntIota]any,
+) (*RenderedResponse, error) {
+	format := strings.ToLower(d.Format)
+	if format == "" {
+		format = common.ResponseFormatJSON
+	}
+	contentType, ok := responseContentTypes[format]
+	if !ok {
+		return nil, fmt.Errorf("response format %s not supported", d.Format)
+	}
+	if d.ContentType != "" {
+		contentType = d.ContentType
+	}
+
+	statusCode, err := resolveResponseStatus(d.HTTPStatusCode, ctx, dependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := RenderedResponse{StatusCode: statusCode, ContentType: contentType, Headers: map[string]string{}}
+	for name, value := range d.Headers {
+		scanned, err := scanResponseString(value, ctx, dependencies)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan header %s: %s", name, err)
+		}
+		rendered.Headers[name] = scanned
+	}
+	for _, cookie := range d.Cookies {
+		scanned, err := scanResponseString(cookie.Value, ctx, dependencies)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan cookie %s: %s", cookie.Name, err)
+		}
+		rendered.Cookies = append(rendered.Cookies, RenderedCookie{ResponseCookie: cookie, Value: scanned})
+	}
+
+	if d.Redirect != nil {
+		location, err := scanResponseString(d.Redirect, ctx, dependencies)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan redirect: %s", err)
+		}
+		if location != "" {
+			rendered.Redirect = location
+			if rendered.StatusCode < 300 || rendered.StatusCode > 399 {
+				rendered.StatusCode = http.StatusFound
+			}
+			return &rendered, nil
+		}
+	}
+
+	if d.FileName != nil {
+		fileName, err := scanResponseString(d.FileName, ctx, dependencies)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan file name: %s", err)
+		}
+		if fileName != "" {
+			rendered.Headers[common.ResponseHeaderDisposition] = fmt.Sprintf("attachment; filename=%q", fileName)
+		}
+	}
+
+	var content any
+	if d.Body != nil {
+		content, err = resolveResponseValue(d.Body, ctx, dependencies)
+	} else {
+		content, err = resolveResponseValue(d.Definition, ctx, dependencies)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve response body: %s", err)
+	}
+
+	switch format {
+	case common.ResponseFormatJSON:
+		rendered.Body, err = json.Marshal(content)
+	case common.ResponseFormatXML:
+		rendered.Body, err = renderXML(content, d.XMLRoot)
+	case common.ResponseFormatText:
+		rendered.Body, err = renderText(content)
+	case common.ResponseFormatCSV:
+		rendered.Body, err = renderCSV(content, d.CSVColumns)
+	case common.ResponseFormatBinary:
+		rendered.Body, err = renderBinary(content)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not render %s response: %s", format, err)
+	}
+	return &rendered, nil
+}
+
+func scanResponseString(value any, ctx context.Context, dependencies map[common.IntIota]any) (string, error) {
+	resolved, err := resolveResponseValue(value, ctx, dependencies)
+	if err != nil || resolved == nil {
+		return "", err
+	}
+	return fmt.Sprint(resolved), nil
+}
+
+func resolveResponseStatus(value any, ctx context.Context, dependencies map[common.IntIota]any) (int, error) {
+	resolved, err := resolveResponseValue(value, ctx, dependencies)
+	if err != nil {
+		return 0, fmt.Errorf("could not resolve response status: %s", err)
+	}
+
+	var status int
+	switch s := resolved.(type) {
+	case nil:
+		return http.StatusOK, nil
+	case string:
+		if status, err = strconv.Atoi(strings.TrimSpace(s)); err != nil {
+			return 0, fmt.Errorf("response status %s is not a number", s)
+		}
+	default:
+		rv := reflect.ValueOf(resolved)
+		switch {
+		case rv.CanInt():
+			status = int(rv.Int())
+		case rv.CanUint():
+			status = int(rv.Uint())
+		case rv.CanFloat() && rv.Float() == float64(int(rv.Float())):
+			status = int(rv.Float())
+		default:
+			return 0, fmt.Errorf("response status %v is not a number", resolved)
+		}
+	}
+	if status < 100 || status > 599 {
+		return 0, fmt.Errorf("response status %d out of range", status)
+	}
+	return status, nil
+}
+
+func resolveResponseValue(value any, ctx context.Context, dependencies map[common.IntIota]any) (any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case Resolvable:
+		return v.Resolve(ctx, dependencies)
+	case []byte:
+		return v, nil
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	switch rv.Kind() {
+	case reflect.Map:
+		mapped := map[string]any{}
+		if err := mapstructure.Decode(rv.Interface(), &mapped); err != nil {
+			return nil, err
+		}
+		if _, ok := mapped[internalTagKey]; ok && len(mapped) == 1 {
+			return configuration.ScanFromInternalTag(mapped, ctx)
+		}
+		var nested Resolvable
+		if err := mapstructure.Decode(mapped, &nested); err == nil && nested.ResolveType != "" && nested.ResolveData != nil {
+			return nested.Resolve(ctx, dependencies)
+		}
+
+		resolved := make(map[string]any, len(mapped))
+		for key, val := range mapped {
+			r, err := resolveResponseValue(val, ctx, dependencies)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", key, err)
+			}
+			resolved[key] = r
+		}
+		return resolved, nil
+	case reflect.Slice, reflect.Array:
+		resolved := make([]any, rv.Len())
+		for idx := range resolved {
+			r, err := resolveResponseValue(rv.Index(idx).Interface(), ctx, dependencies)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %s", idx, err)
+			}
+			resolved[idx] = r
+		}
+		return resolved, nil
+	default:
+		return rv.Interface(), nil
+	}
+}
+
+func renderText(content any) ([]byte, error) {
+	switch c := content.(type) {
+	case nil:
+		return []byte{}, nil
+	case string:
+		return []byte(c), nil
+	case []byte:
+		return c, nil
+	}
+	switch reflect.ValueOf(content).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return json.Marshal(content)
+	default:
+		return []byte(fmt.Sprint(content)), nil
+	}
+}
+
+func renderBinary(content any) ([]byte, error) {
+	switch c := content.(type) {
+	case nil:
+		return []byte{}, nil
+	case []byte:
+		return c, nil
+	case string:
+		return base64.StdEncoding.DecodeString(c)
+	default:
+		return nil, fmt.Errorf("binary body must be a base64 string")
+	}
+}
+
+func renderCSV(content any, columns []string) ([]byte, error) {
+	rows, ok := content.([]any)
+	if !ok {
+		if content == nil {
+			rows = []any{}
+		} else {
+			return nil, fmt.Errorf("csv body must be an array")
+		}
+	}
+
+	if len(columns) == 0 {
+		seen := map[string]bool{}
+		for _, row := range rows {
+			if rowMap, ok := row.(map[string]any); ok {
+				for key := range rowMap {
+					if !seen[key] {
+						seen[key] = true
+						columns = append(columns, key)
+					}
+				}
+			}
+		}
+		sort.Strings(columns)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if len(columns) > 0 {
+		if err := writer.Write(columns); err != nil {
+			return nil, err
+		}
+	}
+	for idx, row := range rows {
+		var record []string
+		switch r := row.(type) {
+		case map[string]any:
+			record = make([]string, len(columns))
+			for cIdx, column := range columns {
+				record[cIdx] = csvValue(r[column])
+			}
+		case []any:
+			record = make([]string, len(r))
+			for cIdx, value := range r {
+				record[cIdx] = csvValue(value)
+			}
+		default:
+			return nil, fmt.Errorf("csv row %d must be an object or an array", idx)
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+func csvValue(value any) string {
+	if value == nil {
+		return ""
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		if marshalled, err := json.Marshal(value); err == nil {
+			return string(marshalled)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+func renderXML(content any, root string) ([]byte, error) {
+	if root == "" {
+		root = "response"
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := writeXMLElement(&buf, root, content); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeXMLElement(buf *bytes.Buffer, name string, value any) error {
+	name = xmlName(name)
+	if value == nil {
+		buf.WriteString(fmt.Sprintf("<%s/>", name))
+		return nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Map:
+		buf.WriteString(fmt.Sprintf("<%s>", name))
+		keys := make([]string, 0, rv.Len())
+		values := map[string]any{}
+		for _, key := range rv.MapKeys() {
+			k := fmt.Sprint(key.Interface())
+			keys = append(keys, k)
+			values[k] = rv.MapIndex(key).Interface()
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := writeXMLChild(buf, k, values[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteString(fmt.Sprintf("</%s>", name))
+	case reflect.Slice, reflect.Array:
+		if _, ok := value.([]byte); ok {
+			return writeXMLText(buf, name, base64.StdEncoding.EncodeToString(value.([]byte)))
+		}
+		buf.WriteString(fmt.Sprintf("<%s>", name))
+		for idx := 0; idx < rv.Len(); idx++ {
+			if err := writeXMLElement(buf, "item", rv.Index(idx).Interface()); err != nil {
+				return err
+			}
+		}
+		buf.WriteString(fmt.Sprintf("</%s>", name))
+	default:
+		return writeXMLText(buf, name, fmt.Sprint(value))
+	}
+	return nil
+}
+
+func writeXMLChild(buf *bytes.Buffer, name string, value any) error {
+	rv := reflect.ValueOf(value)
+	if value != nil && (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) {
+		if _, ok := value.([]byte); !ok {
+			for idx := 0; idx < rv.Len(); idx++ {
+				if err := writeXMLElement(buf, name, rv.Index(idx).Interface()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	return writeXMLElement(buf, name, value)
+}
+
+func writeXMLText(buf *bytes.Buffer, name string, text string) error {
+	buf.WriteString(fmt.Sprintf("<%s>", name))
+	if err := xml.EscapeText(buf, []byte(text)); err != nil {
+		return err
+	}
+	buf.WriteString(fmt.Sprintf("</%s>", name))
+	return nil
+}
+
+func xmlName(name string) string {
+	sanitized := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+	if sanitized == "" || !(unicode.IsLetter(rune(sanitized[0])) || sanitized[0] == '_') {
+		sanitized = "_" + sanitized
+	}
+	return sanitized
+}
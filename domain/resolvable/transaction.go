@@ -0,0 +1,147 @@
+package resolvable
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"ifttt/handler/common"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+type transaction struct {
+	Do         []Resolvable `json:"do" mapstructure:"do"`
+	Isolation  string       `json:"isolation" mapstructure:"isolation"`
+	ReadOnly   bool         `json:"readOnly" mapstructure:"readOnly"`
+	Timeout    uint         `json:"timeout" mapstructure:"timeout"`
+	DataSource string       `json:"dataSource" mapstructure:"dataSource"`
+}
+
+type transactionScope struct {
+	tx         *sql.Tx
+	dataSource string
+	isolation  string
+	readOnly   bool
+	mtx        sync.Mutex
+}
+
+type txHandle struct {
+	tx    *sql.Tx
+	scope *transactionScope
+}
+
+var isolationLevels = map[string]sql.IsolationLevel{
+	"":                                sql.LevelDefault,
+	common.TxIsolationReadUncommitted: sql.LevelReadUncommitted,
+	common.TxIsolationReadCommitted:   sql.LevelReadCommitted,
+	common.TxIsolationRepeatableRead:  sql.LevelRepeatableRead,
+	common.TxIsolationSerializable:    sql.LevelSerializable,
+}
+
+func (t *transaction) Resolve(ctx context.Context, dependencies map[common.IntIota]any) (any, error) {
+	dataSource, err := getDataSource(t.DataSource, dependencies)
+	if err != nil {
+		return nil, fmt.Errorf("method *transaction: %s", err)
+	}
+
+	isolation, ok := isolationLevels[t.Isolation]
+	if !ok {
+		return nil, fmt.Errorf("isolation level %s not found", t.Isolation)
+	}
+
+	if t.Timeout > 0 {
+		timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(t.Timeout)*time.Millisecond)
+		defer cancel()
+		ctx = timeoutCtx
+	}
+
+	if scope := getTransactionScope(ctx); scope != nil {
+		if err := scope.checkDataSource(dataSource); err != nil {
+			return nil, err
+		}
+		if err := scope.checkOptions(t); err != nil {
+			return nil, err
+		}
+		return ResolveArrayMust(&t.Do, ctx, dependencies)
+	}
+
+	tx, err := dataSource.RawQueryRepo.BeginTx(ctx, &sql.TxOptions{Isolation: isolation, ReadOnly: t.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("could not begin tx: %s", err)
+	}
+
+	txCtx := context.WithValue(ctx, common.ContextTransaction, &transactionScope{
+		tx: tx, dataSource: dataSource.Name, isolation: t.Isolation, readOnly: t.ReadOnly,
+	})
+	results, err := ResolveArrayMust(&t.Do, txCtx, dependencies)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, fmt.Errorf("attempted rollback on error: %s. rollback failed %s", err, rollbackErr)
+		}
+		return nil, fmt.Errorf("rolled back. error: %s", err)
+	} else if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit failed: %s", err)
+	}
+
+	return results, nil
+}
+
+func getTransactionScope(ctx context.Context) *transactionScope {
+	if scope, ok := ctx.Value(common.ContextTransaction).(*transactionScope); ok {
+		return scope
+	}
+	return nil
+}
+
+func (s *transactionScope) checkDataSource(dataSource *DataSource) error {
+	if s.dataSource != dataSource.Name {
+		return fmt.Errorf("data source %s cannot be used inside a transaction on %s", dataSource.Name, s.dataSource)
+	}
+	return nil
+}
+
+func acquireTx(dataSource *DataSource, ctx context.Context) (*txHandle, error) {
+	if scope := getTransactionScope(ctx); scope != nil {
+		if err := scope.checkDataSource(dataSource); err != nil {
+			return nil, err
+		}
+		scope.mtx.Lock()
+		return &txHandle{tx: scope.tx, scope: scope}, nil
+	}
+
+	tx, err := dataSource.RawQueryRepo.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not begin tx: %s", err)
+	}
+	return &txHandle{tx: tx}, nil
+}
+
+func (h *txHandle) finish(err error) error {
+	if h.scope != nil {
+		h.scope.mtx.Unlock()
+		return err
+	}
+
+	if err != nil {
+		if rollbackErr := h.tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("attempted rollback on error: %s. rollback failed %s", err, rollbackErr)
+		}
+		return fmt.Errorf("rolled back. error: %s", err)
+	} else if err := h.tx.Commit(); err != nil {
+		return fmt.Errorf("commit failed: %s", err)
+	}
+	return nil
+}
+
+func (s *transactionScope) checkOptions(t *transaction) error {
+	if t.Isolation != "" && t.Isolation != s.isolation {
+		return fmt.Errorf("nested transaction cannot use isolation %s inside a transaction with isolation %s",
+			t.Isolation, lo.Ternary(s.isolation == "", "default", s.isolation))
+	}
+	if t.ReadOnly && !s.readOnly {
+		return fmt.Errorf("nested read only transaction cannot run inside a read write transaction")
+	}
+	return nil
+}
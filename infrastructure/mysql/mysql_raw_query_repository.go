@@ -3,6 +3,7 @@ package infrastructure
 import (
 	"context"
 	"database/sql"
+	"ifttt/handler/common"
 )
 
 type MySqlRawQueryRepository struct {
@@ -13,8 +14,12 @@ func NewMySqlRawQueryRepository(base *MySqlBaseRepository) *MySqlRawQueryReposit
 	return &MySqlRawQueryRepository{MySqlBaseRepository: base}
 }
 
-func (m *MySqlRawQueryRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
-	return m.client.BeginTx(ctx, &sql.TxOptions{})
+func (m *MySqlRawQueryRepository) Dialect() string {
+	return common.DialectMySQL
+}
+
+func (m *MySqlRawQueryRepository) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return m.client.BeginTx(ctx, opts)
 }
 
 func (m *MySqlRawQueryRepository) Scan(tx *sql.Tx, queryString string, parameters []any, ctx context.Context) (*[]map[string]any, int, error) {
@@ -24,11 +29,27 @@ func (m *MySqlRawQueryRepository) Scan(tx *sql.Tx, queryString string, parameter
 	}
 	defer rows.Close()
 
-	if mappedRows, err := m.scan(rows); err != nil {
+	mappedRows := []map[string]any{}
+	if count, err := common.ScanRows(rows, func(row map[string]any) error {
+		mappedRows = append(mappedRows, row)
+		return nil
+	}); err != nil {
 		return nil, 0, err
 	} else {
-		return mappedRows, len(*mappedRows), nil
+		return &mappedRows, count, nil
+	}
+}
+
+func (m *MySqlRawQueryRepository) Stream(
+	tx *sql.Tx, queryString string, parameters []any, ctx context.Context, fn func(row map[string]any) error,
+) (int, error) {
+	rows, err := tx.QueryContext(ctx, queryString, parameters...)
+	if err != nil {
+		return 0, err
 	}
+	defer rows.Close()
+
+	return common.ScanRows(rows, fn)
 }
 
 func (m *MySqlRawQueryRepository) Exec(tx *sql.Tx, queryString string, parameters []any, ctx context.Context) (int, error) {
@@ -40,68 +61,3 @@ func (m *MySqlRawQueryRepository) Exec(tx *sql.Tx, queryString string, parameter
 		return int(affected), nil
 	}
 }
-
-func (m *MySqlRawQueryRepository) scan(rows *sql.Rows) (*[]map[string]any, error) {
-	mappedRows := []map[string]any{}
-	columns, err := rows.Columns()
-	if err != nil {
-		return nil, err
-	}
-
-	scanCols := make([]any, len(columns))
-	for idx := range scanCols {
-		var a any
-		scanCols[idx] = &a
-	}
-
-	strDescision := make(map[int]bool, len(columns))
-	if rows.Next() {
-		if err := rows.Scan(scanCols...); err != nil {
-			return nil, err
-		}
-
-		m := make(map[string]any, len(scanCols))
-		for idx, v := range scanCols {
-			key := columns[idx]
-			retrieved := v.(*any)
-			if *retrieved == nil {
-				strDescision[idx] = true
-				m[key] = nil
-			} else if v, ok := (*retrieved).([]byte); ok {
-				strDescision[idx] = true
-				m[key] = string(v)
-			} else {
-				m[key] = *retrieved
-			}
-		}
-		mappedRows = append(mappedRows, m)
-	}
-
-	for rows.Next() {
-		scanCols := make([]any, len(columns))
-		for idx := range scanCols {
-			var a any
-			scanCols[idx] = &a
-		}
-		if err := rows.Scan(scanCols...); err != nil {
-			return nil, err
-		}
-
-		m := make(map[string]any, len(columns))
-		for idx, v := range scanCols {
-			key := columns[idx]
-			retrieved := v.(*any)
-			if strDescision[idx] {
-				if strcast, ok := (*retrieved).([]byte); ok {
-					m[key] = string(strcast)
-				} else {
-					m[key] = *retrieved
-				}
-			} else {
-				m[key] = *retrieved
-			}
-		}
-		mappedRows = append(mappedRows, m)
-	}
-	return &mappedRows, nil
-}
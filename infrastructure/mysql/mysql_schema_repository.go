@@ -0,0 +1,70 @@
+package infrastructure
+
+import (
+	"context"
+	"ifttt/handler/domain/orm_schema"
+)
+
+type MySqlSchemaRepository struct {
+	*MySqlBaseRepository
+}
+
+func NewMySqlSchemaRepository(base *MySqlBaseRepository) *MySqlSchemaRepository {
+	return &MySqlSchemaRepository{MySqlBaseRepository: base}
+}
+
+func (m *MySqlSchemaRepository) IntrospectSchema(ctx context.Context) (*orm_schema.DatabaseSchema, error) {
+	schema := orm_schema.DatabaseSchema{}
+
+	columnRows, err := m.client.QueryContext(ctx, `
+		SELECT c.TABLE_NAME, c.COLUMN_NAME, c.COLUMN_TYPE, c.IS_NULLABLE, c.COLUMN_KEY
+		FROM information_schema.COLUMNS c
+		JOIN information_schema.TABLES t
+			ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
+		WHERE c.TABLE_SCHEMA = DATABASE() AND t.TABLE_TYPE = 'BASE TABLE'
+		ORDER BY c.TABLE_NAME, c.ORDINAL_POSITION`)
+	if err != nil {
+		return nil, err
+	}
+	defer columnRows.Close()
+
+	for columnRows.Next() {
+		var (
+			column           orm_schema.SchemaColumn
+			nullable, colKey string
+		)
+		if err := columnRows.Scan(&column.Table, &column.Name, &column.DataType, &nullable, &colKey); err != nil {
+			return nil, err
+		}
+		column.Nullable = nullable == "YES"
+		column.PrimaryKey = colKey == "PRI"
+		column.Unique = colKey == "UNI"
+		schema.Columns = append(schema.Columns, column)
+	}
+	if err := columnRows.Err(); err != nil {
+		return nil, err
+	}
+
+	fkRows, err := m.client.QueryContext(ctx, `
+		SELECT TABLE_NAME, COLUMN_NAME, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = DATABASE() AND REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY TABLE_NAME, CONSTRAINT_NAME, ORDINAL_POSITION`)
+	if err != nil {
+		return nil, err
+	}
+	defer fkRows.Close()
+
+	for fkRows.Next() {
+		var fk orm_schema.SchemaForeignKey
+		if err := fkRows.Scan(&fk.Table, &fk.Column, &fk.ReferencesTable, &fk.ReferencesColumn); err != nil {
+			return nil, err
+		}
+		schema.ForeignKeys = append(schema.ForeignKeys, fk)
+	}
+	if err := fkRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &schema, nil
+}
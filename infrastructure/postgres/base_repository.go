@@ -16,10 +16,16 @@ func NewPostgresBaseRepository(client *gorm.DB, migrate bool) *PostgresBaseRepos
 	}
 	if migrate {
 		if err := client.AutoMigrate(
-		// &apis{}, &crons{}, &trigger_flows{}, &rules{},
+			// &apis{}, &crons{}, &trigger_flows{}, &rules{},
+			&events{},
 		); err != nil {
 			panic(fmt.Errorf("could not automigrate gorm:%s", err))
 		}
+		if migrator := client.Migrator(); !migrator.HasColumn(&response_profile{}, "Events") {
+			if err := migrator.AddColumn(&response_profile{}, "Events"); err != nil {
+				panic(fmt.Errorf("could not add events to response profiles:%s", err))
+			}
+		}
 	}
 	return &PostgresBaseRepository{client: client}
 }
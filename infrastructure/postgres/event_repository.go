@@ -0,0 +1,35 @@
+package infrastructure
+
+import (
+	"ifttt/handler/domain/configuration"
+
+	"gorm.io/gorm"
+)
+
+type PostgresEventRepository struct {
+	*PostgresBaseRepository
+}
+
+func NewPostgresEventRepository(base *PostgresBaseRepository) *PostgresEventRepository {
+	return &PostgresEventRepository{PostgresBaseRepository: base}
+}
+
+func (r *PostgresEventRepository) GetAllEvents() (*[]configuration.Event, error) {
+	var pgEvents []events
+	if err := r.client.Find(&pgEvents).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	dEvents := make([]configuration.Event, 0, len(pgEvents))
+	for _, e := range pgEvents {
+		dEvent, err := e.toDomain()
+		if err != nil {
+			return nil, err
+		}
+		dEvents = append(dEvents, *dEvent)
+	}
+	return &dEvents, nil
+}
@@ -81,6 +81,12 @@ func (a *apis) toDomain() (*api.Api, error) {
 		return nil, err
 	}
 
+	if len(a.RequestSchema.Bytes) > 0 {
+		if err := json.Unmarshal(a.RequestSchema.Bytes, &domainApi.RequestSchema); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := json.Unmarshal(a.Response.Bytes, &domainApi.Response); err != nil {
 		return nil, err
 	}
@@ -149,10 +155,17 @@ func (p *response_profile) toDomain() (*configuration.ResponseProfile, error) {
 		ID:                 p.ID,
 		Name:               p.Name,
 		ResponseHTTPStatus: p.ResponseHTTPStatus,
+		Internal:           p.Internal,
+		ParentID:           p.ParentID,
 	}
 	if err := json.Unmarshal(p.BodyFormat.Bytes, &dProfile.BodyFormat); err != nil {
 		return nil, err
 	}
+	if len(p.Events.Bytes) > 0 {
+		if err := json.Unmarshal(p.Events.Bytes, &dProfile.Events); err != nil {
+			return nil, err
+		}
+	}
 	return &dProfile, nil
 }
 
@@ -164,3 +177,19 @@ func (p *internal_tags) toDomain() *configuration.InternalTag {
 
 	return &dPTag
 }
+
+func (e *events) toDomain() (*configuration.Event, error) {
+	dEvent := configuration.Event{
+		ID:          e.ID,
+		Code:        e.Code,
+		Name:        e.Name,
+		Description: e.Description,
+		HTTPStatus:  e.HTTPStatus,
+	}
+	if len(e.Body.Bytes) > 0 {
+		if err := json.Unmarshal(e.Body.Bytes, &dEvent.Body); err != nil {
+			return nil, err
+		}
+	}
+	return &dEvent, nil
+}
@@ -0,0 +1,31 @@
+package infrastructure
+
+import (
+	"ifttt/handler/domain/configuration"
+
+	"gorm.io/gorm"
+)
+
+type PostgresInternalTagRepository struct {
+	*PostgresBaseRepository
+}
+
+func NewPostgresInternalTagRepository(base *PostgresBaseRepository) *PostgresInternalTagRepository {
+	return &PostgresInternalTagRepository{PostgresBaseRepository: base}
+}
+
+func (r *PostgresInternalTagRepository) GetAllInternalTags() (*[]configuration.InternalTag, error) {
+	var pgTags []internal_tags
+	if err := r.client.Find(&pgTags).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	dTags := make([]configuration.InternalTag, 0, len(pgTags))
+	for _, t := range pgTags {
+		dTags = append(dTags, *t.toDomain())
+	}
+	return &dTags, nil
+}
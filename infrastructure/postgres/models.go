@@ -18,15 +18,16 @@ type crons struct {
 
 type apis struct {
 	gorm.Model
-	Name         string          `gorm:"type:varchar(50);not null;unique" mapstructure:"name"`
-	Path         string          `gorm:"type:varchar(50);not null;unique" mapstructure:"path"`
-	Method       string          `gorm:"type:varchar(10);not null" mapstructure:"method"`
-	Description  string          `gorm:"type:text;default:''" mapstructure:"description"`
-	PreConfig    pgtype.JSONB    `gorm:"type:jsonb;default:'[]';not null" mapstructure:"preConfig"`
-	Request      pgtype.JSONB    `gorm:"type:jsonb;default:'{}';not null" mapstructure:"request"`
-	Response     pgtype.JSONB    `gorm:"type:jsonb;default:'{}';not null" mapstructure:"response"`
-	Triggers     []trigger_flows `gorm:"many2many:api_trigger_flows_main;joinForeignKey:ApiId;joinReferences:FlowId;" mapstructure:"triggerFlows"`
-	TriggerFlows pgtype.JSONB    `gorm:"type:jsonb;default:'{}';not null" mapstructure:"triggerConditions"`
+	Name          string          `gorm:"type:varchar(50);not null;unique" mapstructure:"name"`
+	Path          string          `gorm:"type:varchar(50);not null;unique" mapstructure:"path"`
+	Method        string          `gorm:"type:varchar(10);not null" mapstructure:"method"`
+	Description   string          `gorm:"type:text;default:''" mapstructure:"description"`
+	PreConfig     pgtype.JSONB    `gorm:"type:jsonb;default:'[]';not null" mapstructure:"preConfig"`
+	Request       pgtype.JSONB    `gorm:"type:jsonb;default:'{}';not null" mapstructure:"request"`
+	RequestSchema pgtype.JSONB    `gorm:"type:jsonb" mapstructure:"requestSchema"`
+	Response      pgtype.JSONB    `gorm:"type:jsonb;default:'{}';not null" mapstructure:"response"`
+	Triggers      []trigger_flows `gorm:"many2many:api_trigger_flows_main;joinForeignKey:ApiId;joinReferences:FlowId;" mapstructure:"triggerFlows"`
+	TriggerFlows  pgtype.JSONB    `gorm:"type:jsonb;default:'{}';not null" mapstructure:"triggerConditions"`
 }
 
 type api_trigger_flow_json struct {
@@ -57,6 +58,9 @@ type orm_model struct {
 	Name                   string            `gorm:"type:varchar(255);not null" mapstructure:"name" json:"name"`
 	Table                  string            `gorm:"type:varchar(255);not null" mapstructure:"table" json:"table"`
 	PrimaryKey             string            `gorm:"type:varchar(255);not null" mapstructure:"primaryKey" json:"primaryKey"`
+	SoftDeleteColumn       string            `gorm:"type:varchar(255);default:''" mapstructure:"softDeleteColumn" json:"softDeleteColumn"`
+	CreatedAtColumn        string            `gorm:"type:varchar(255);default:''" mapstructure:"createdAtColumn" json:"createdAtColumn"`
+	UpdatedAtColumn        string            `gorm:"type:varchar(255);default:''" mapstructure:"updatedAtColumn" json:"updatedAtColumn"`
 	Projections            []orm_projection  `gorm:"foreignKey:ModelID" mapstructure:"projections" json:"projections"`
 	OwningAssociations     []orm_association `gorm:"foreignKey:OwningModelID" mapstructure:"owningAssociations" json:"owningAssociations"`
 	ReferencedAssociations []orm_association `gorm:"foreignKey:ReferencesModelID" mapstructure:"referencedAssociations" json:"referencedAssociations"`
@@ -95,6 +99,7 @@ type response_profile struct {
 	ResponseHTTPStatus int                 `gorm:"not null" json:"responseHTTPStatus" mapstructure:"responseHTTPStatus"`
 	BodyFormat         pgtype.JSONB        `gorm:"type:jsonb;default:'{}';not null" json:"bodyFormat" mapstructure:"bodyFormat"`
 	Internal           bool                `gorm:"not null" json:"internal" mapstructure:"internal"`
+	Events             pgtype.JSONB        `gorm:"type:jsonb;default:'[]';not null" json:"events" mapstructure:"events"`
 	ParentID           *uint               `json:"parentId" mapstructure:"parentId"`
 	MappedProfile      *[]response_profile `gorm:"foreignKey:ParentID" json:"mappedProfile" mapstructure:"mappedProfile"`
 }
@@ -103,3 +108,12 @@ type internal_tags struct {
 	gorm.Model
 	Name string `gorm:"unique" json:"name" mapstructure:"name"`
 }
+
+type events struct {
+	gorm.Model
+	Code        uint         `gorm:"unique;not null" json:"code" mapstructure:"code"`
+	Name        string       `gorm:"not null" json:"name" mapstructure:"name"`
+	Description string       `json:"description" mapstructure:"description"`
+	HTTPStatus  int          `json:"httpStatus" mapstructure:"httpStatus"`
+	Body        pgtype.JSONB `gorm:"type:jsonb" json:"body" mapstructure:"body"`
+}
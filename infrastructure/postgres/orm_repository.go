@@ -1,9 +1,12 @@
 package infrastructure
 
 import (
+	"errors"
+	"fmt"
 	"ifttt/handler/domain/orm_schema"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PostgresOrmRepository struct {
@@ -52,3 +55,104 @@ func (o *PostgresOrmRepository) GetAllAssociations() (*[]orm_schema.ModelAssocia
 	}
 	return &dAssociations, nil
 }
+
+// SaveModels keeps models and associations that already exist, so hand tuned
+// projections survive a re-run, unless overwrite is set. It returns what was kept
+func (o *PostgresOrmRepository) SaveModels(bundle *orm_schema.ModelBundle, overwrite bool) ([]string, error) {
+	kept := []string{}
+	err := o.client.Transaction(func(tx *gorm.DB) error {
+		modelIDs := map[string]uint{}
+		for _, m := range bundle.Models {
+			var pgModel orm_model
+			if err := tx.Where("name = ?", m.Name).First(&pgModel).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+				pgModel = orm_model{Name: m.Name}
+			} else if err != nil {
+				return err
+			} else if !overwrite {
+				kept = append(kept, fmt.Sprintf("model %s", m.Name))
+				modelIDs[m.Name] = pgModel.ID
+				continue
+			}
+			pgModel.Table = m.Table
+			pgModel.PrimaryKey = m.PrimaryKey
+			pgModel.SoftDeleteColumn = m.SoftDeleteColumn
+			pgModel.CreatedAtColumn = m.CreatedAtColumn
+			pgModel.UpdatedAtColumn = m.UpdatedAtColumn
+			if err := tx.Omit(clause.Associations).Save(&pgModel).Error; err != nil {
+				return fmt.Errorf("could not save model %s: %s", m.Name, err)
+			}
+
+			if err := tx.Where("model_id = ?", pgModel.ID).Delete(&orm_projection{}).Error; err != nil {
+				return fmt.Errorf("could not clear projections for %s: %s", m.Name, err)
+			}
+			projections := make([]orm_projection, len(m.Projections))
+			for idx, p := range m.Projections {
+				projections[idx] = orm_projection{
+					ModelID:    pgModel.ID,
+					Column:     p.Column,
+					As:         p.As,
+					SchemaType: p.SchemaType,
+					ModelType:  p.ModelType,
+					NotNull:    p.NotNull,
+				}
+			}
+			if len(projections) > 0 {
+				if err := tx.Create(&projections).Error; err != nil {
+					return fmt.Errorf("could not save projections for %s: %s", m.Name, err)
+				}
+			}
+			modelIDs[m.Name] = pgModel.ID
+		}
+
+		modelID := func(name string) (uint, error) {
+			if id, ok := modelIDs[name]; ok {
+				return id, nil
+			}
+			var pgModel orm_model
+			if err := tx.Where("name = ?", name).First(&pgModel).Error; err != nil {
+				return 0, fmt.Errorf("model %s not found: %s", name, err)
+			}
+			modelIDs[name] = pgModel.ID
+			return pgModel.ID, nil
+		}
+
+		for _, a := range bundle.Associations {
+			owningID, err := modelID(a.OwningModel.Name)
+			if err != nil {
+				return err
+			}
+			referencesID, err := modelID(a.ReferencesModel.Name)
+			if err != nil {
+				return err
+			}
+
+			var pgAssociation orm_association
+			if err := tx.Where("name = ?", a.Name).First(&pgAssociation).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+				pgAssociation = orm_association{Name: a.Name}
+			} else if err != nil {
+				return err
+			} else if !overwrite {
+				kept = append(kept, fmt.Sprintf("association %s", a.Name))
+				continue
+			}
+			pgAssociation.Type = a.Type
+			pgAssociation.TableName = a.TableName
+			pgAssociation.ColumnName = a.ColumnName
+			pgAssociation.ReferencesTable = a.ReferencesTable
+			pgAssociation.ReferencesField = a.ReferencesField
+			pgAssociation.JoinTable = a.JoinTable
+			pgAssociation.JoinTableSourceField = a.JoinTableSourceField
+			pgAssociation.JoinTableTargetField = a.JoinTableTargetField
+			pgAssociation.OwningModelID = owningID
+			pgAssociation.ReferencesModelID = referencesID
+			if err := tx.Omit(clause.Associations).Save(&pgAssociation).Error; err != nil {
+				return fmt.Errorf("could not save association %s: %s", a.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return kept, nil
+}
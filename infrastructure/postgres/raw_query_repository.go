@@ -2,6 +2,8 @@ package infrastructure
 
 import (
 	"context"
+	"database/sql"
+	"ifttt/handler/common"
 )
 
 type PostgresRawQueryRepository struct {
@@ -12,18 +14,48 @@ func NewPostgresRawQueryRepository(base *PostgresBaseRepository) *PostgresRawQue
 	return &PostgresRawQueryRepository{PostgresBaseRepository: base}
 }
 
-func (p *PostgresRawQueryRepository) ScanPositional(queryString string, parameters []any, ctx context.Context) (*[]map[string]any, error) {
-	return nil, nil
+func (p *PostgresRawQueryRepository) Dialect() string {
+	return common.DialectPostgres
 }
 
-func (p *PostgresRawQueryRepository) ScanNamed(queryString string, parameters map[string]any, ctx context.Context) (*[]map[string]any, error) {
-	return nil, nil
+func (p *PostgresRawQueryRepository) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	db, err := p.client.DB()
+	if err != nil {
+		return nil, err
+	}
+	return db.BeginTx(ctx, opts)
 }
 
-func (p *PostgresRawQueryRepository) ExecPositional(queryString string, parameters []any, ctx context.Context) error {
-	return nil
+func (p *PostgresRawQueryRepository) Scan(tx *sql.Tx, queryString string, parameters []any, ctx context.Context) (*[]map[string]any, int, error) {
+	mappedRows := []map[string]any{}
+	if count, err := p.Stream(tx, queryString, parameters, ctx, func(row map[string]any) error {
+		mappedRows = append(mappedRows, row)
+		return nil
+	}); err != nil {
+		return nil, 0, err
+	} else {
+		return &mappedRows, count, nil
+	}
 }
 
-func (p *PostgresRawQueryRepository) ExecNamed(queryString string, parameters map[string]any, ctx context.Context) error {
-	return nil
+func (p *PostgresRawQueryRepository) Exec(tx *sql.Tx, queryString string, parameters []any, ctx context.Context) (int, error) {
+	if results, err := tx.ExecContext(ctx, queryString, parameters...); err != nil {
+		return 0, err
+	} else if affected, err := results.RowsAffected(); err != nil {
+		return 0, err
+	} else {
+		return int(affected), nil
+	}
+}
+
+func (p *PostgresRawQueryRepository) Stream(
+	tx *sql.Tx, queryString string, parameters []any, ctx context.Context, fn func(row map[string]any) error,
+) (int, error) {
+	rows, err := tx.QueryContext(ctx, queryString, parameters...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	return common.ScanRows(rows, fn)
 }
@@ -0,0 +1,99 @@
+package infrastructure
+
+import (
+	"context"
+	"ifttt/handler/domain/orm_schema"
+)
+
+type PostgresSchemaRepository struct {
+	*PostgresBaseRepository
+}
+
+func NewPostgresSchemaRepository(base *PostgresBaseRepository) *PostgresSchemaRepository {
+	return &PostgresSchemaRepository{PostgresBaseRepository: base}
+}
+
+type postgresSchemaColumn struct {
+	TableName  string
+	ColumnName string
+	DataType   string
+	IsNullable string
+}
+
+type postgresSchemaConstraint struct {
+	TableName        string
+	ColumnName       string
+	ConstraintType   string
+	ColumnCount      int
+	ReferencesTable  *string
+	ReferencesColumn *string
+}
+
+func (p *PostgresSchemaRepository) IntrospectSchema(ctx context.Context) (*orm_schema.DatabaseSchema, error) {
+	var columns []postgresSchemaColumn
+	if err := p.client.WithContext(ctx).Raw(`
+		SELECT c.table_name, c.column_name, c.data_type, c.is_nullable
+		FROM information_schema.columns c
+		JOIN information_schema.tables t
+			ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+		WHERE c.table_schema = current_schema() AND t.table_type = 'BASE TABLE'
+		ORDER BY c.table_name, c.ordinal_position`).Scan(&columns).Error; err != nil {
+		return nil, err
+	}
+
+	var constraints []postgresSchemaConstraint
+	if err := p.client.WithContext(ctx).Raw(`
+		SELECT kcu.table_name, kcu.column_name, tc.constraint_type,
+			COUNT(*) OVER (PARTITION BY tc.constraint_name) AS column_count,
+			fk.table_name AS references_table, fk.column_name AS references_column
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.key_column_usage kcu
+			ON kcu.constraint_schema = tc.constraint_schema AND kcu.constraint_name = tc.constraint_name
+		LEFT JOIN information_schema.referential_constraints rc
+			ON rc.constraint_schema = tc.constraint_schema AND rc.constraint_name = tc.constraint_name
+		LEFT JOIN information_schema.key_column_usage fk
+			ON fk.constraint_schema = rc.unique_constraint_schema
+			AND fk.constraint_name = rc.unique_constraint_name
+			AND fk.ordinal_position = kcu.position_in_unique_constraint
+		WHERE tc.table_schema = current_schema()
+			AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE', 'FOREIGN KEY')
+		ORDER BY kcu.table_name, tc.constraint_name, kcu.ordinal_position`).Scan(&constraints).Error; err != nil {
+		return nil, err
+	}
+
+	primaryKeys := map[string]bool{}
+	uniques := map[string]bool{}
+	schema := orm_schema.DatabaseSchema{}
+	for _, c := range constraints {
+		key := c.TableName + "." + c.ColumnName
+		switch c.ConstraintType {
+		case "PRIMARY KEY":
+			primaryKeys[key] = true
+		case "UNIQUE":
+			uniques[key] = uniques[key] || c.ColumnCount == 1
+		case "FOREIGN KEY":
+			if c.ReferencesTable != nil && c.ReferencesColumn != nil {
+				schema.ForeignKeys = append(schema.ForeignKeys, orm_schema.SchemaForeignKey{
+					Table:            c.TableName,
+					Column:           c.ColumnName,
+					ReferencesTable:  *c.ReferencesTable,
+					ReferencesColumn: *c.ReferencesColumn,
+				})
+			}
+		}
+	}
+
+	for _, c := range columns {
+		key := c.TableName + "." + c.ColumnName
+		schema.Columns = append(schema.Columns, orm_schema.SchemaColumn{
+			Table:      c.TableName,
+			Name:       c.ColumnName,
+			DataType:   c.DataType,
+			Nullable:   c.IsNullable == "YES",
+			PrimaryKey: primaryKeys[key],
+			Unique:     uniques[key],
+		})
+	}
+
+	return &schema, nil
+}
@@ -0,0 +1,16 @@
+package infrastructure
+
+import (
+	"database/sql"
+)
+
+type SqliteBaseRepository struct {
+	client *sql.DB
+}
+
+func NewSqliteBaseRepository(client *sql.DB) *SqliteBaseRepository {
+	if client == nil {
+		panic("missing sqlite client")
+	}
+	return &SqliteBaseRepository{client: client}
+}
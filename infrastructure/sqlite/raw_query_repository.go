@@ -0,0 +1,57 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"ifttt/handler/common"
+)
+
+type SqliteRawQueryRepository struct {
+	*SqliteBaseRepository
+}
+
+func NewSqliteRawQueryRepository(base *SqliteBaseRepository) *SqliteRawQueryRepository {
+	return &SqliteRawQueryRepository{SqliteBaseRepository: base}
+}
+
+func (s *SqliteRawQueryRepository) Dialect() string {
+	return common.DialectSQLite
+}
+
+func (s *SqliteRawQueryRepository) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return s.client.BeginTx(ctx, opts)
+}
+
+func (s *SqliteRawQueryRepository) Scan(tx *sql.Tx, queryString string, parameters []any, ctx context.Context) (*[]map[string]any, int, error) {
+	mappedRows := []map[string]any{}
+	if count, err := s.Stream(tx, queryString, parameters, ctx, func(row map[string]any) error {
+		mappedRows = append(mappedRows, row)
+		return nil
+	}); err != nil {
+		return nil, 0, err
+	} else {
+		return &mappedRows, count, nil
+	}
+}
+
+func (s *SqliteRawQueryRepository) Exec(tx *sql.Tx, queryString string, parameters []any, ctx context.Context) (int, error) {
+	if results, err := tx.ExecContext(ctx, queryString, parameters...); err != nil {
+		return 0, err
+	} else if affected, err := results.RowsAffected(); err != nil {
+		return 0, err
+	} else {
+		return int(affected), nil
+	}
+}
+
+func (s *SqliteRawQueryRepository) Stream(
+	tx *sql.Tx, queryString string, parameters []any, ctx context.Context, fn func(row map[string]any) error,
+) (int, error) {
+	rows, err := tx.QueryContext(ctx, queryString, parameters...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	return common.ScanRows(rows, fn)
+}
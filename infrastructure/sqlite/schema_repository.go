@@ -0,0 +1,145 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"ifttt/handler/common"
+	"ifttt/handler/domain/orm_schema"
+)
+
+type SqliteSchemaRepository struct {
+	*SqliteBaseRepository
+}
+
+func NewSqliteSchemaRepository(base *SqliteBaseRepository) *SqliteSchemaRepository {
+	return &SqliteSchemaRepository{SqliteBaseRepository: base}
+}
+
+func (s *SqliteSchemaRepository) IntrospectSchema(ctx context.Context) (*orm_schema.DatabaseSchema, error) {
+	tables, err := s.tables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := orm_schema.DatabaseSchema{}
+	for _, table := range tables {
+		uniques, err := s.uniqueColumns(table, ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.query(ctx, func(rows *sql.Rows) error {
+			var (
+				cid, notNull, pk int
+				name, dataType   string
+				defaultValue     any
+			)
+			if err := rows.Scan(&cid, &name, &dataType, &notNull, &defaultValue, &pk); err != nil {
+				return err
+			}
+			schema.Columns = append(schema.Columns, orm_schema.SchemaColumn{
+				Table:      table,
+				Name:       name,
+				DataType:   dataType,
+				Nullable:   notNull == 0 && pk == 0,
+				PrimaryKey: pk > 0,
+				Unique:     uniques[name],
+			})
+			return nil
+		}, "PRAGMA table_info("+common.SqlQuoteIdentifier(common.DialectSQLite, table)+")"); err != nil {
+			return nil, err
+		}
+
+		if err := s.query(ctx, func(rows *sql.Rows) error {
+			var (
+				id, seq                       int
+				referencesTable, from         string
+				to                            sql.NullString
+				onUpdate, onDelete, matchType string
+			)
+			if err := rows.Scan(&id, &seq, &referencesTable, &from, &to, &onUpdate, &onDelete, &matchType); err != nil {
+				return err
+			}
+			schema.ForeignKeys = append(schema.ForeignKeys, orm_schema.SchemaForeignKey{
+				Table:            table,
+				Column:           from,
+				ReferencesTable:  referencesTable,
+				ReferencesColumn: to.String,
+			})
+			return nil
+		}, "PRAGMA foreign_key_list("+common.SqlQuoteIdentifier(common.DialectSQLite, table)+")"); err != nil {
+			return nil, err
+		}
+	}
+
+	return &schema, nil
+}
+
+func (s *SqliteSchemaRepository) tables(ctx context.Context) ([]string, error) {
+	tables := []string{}
+	err := s.query(ctx, func(rows *sql.Rows) error {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		tables = append(tables, name)
+		return nil
+	}, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	return tables, err
+}
+
+func (s *SqliteSchemaRepository) uniqueColumns(table string, ctx context.Context) (map[string]bool, error) {
+	indexes := []string{}
+	if err := s.query(ctx, func(rows *sql.Rows) error {
+		var (
+			seq, unique, partial int
+			name, origin         string
+		)
+		if err := rows.Scan(&seq, &name, &unique, &origin, &partial); err != nil {
+			return err
+		}
+		if unique == 1 && partial == 0 {
+			indexes = append(indexes, name)
+		}
+		return nil
+	}, "PRAGMA index_list("+common.SqlQuoteIdentifier(common.DialectSQLite, table)+")"); err != nil {
+		return nil, err
+	}
+
+	uniques := map[string]bool{}
+	for _, index := range indexes {
+		columns := []string{}
+		if err := s.query(ctx, func(rows *sql.Rows) error {
+			var (
+				seqno, cid int
+				name       sql.NullString
+			)
+			if err := rows.Scan(&seqno, &cid, &name); err != nil {
+				return err
+			}
+			columns = append(columns, name.String)
+			return nil
+		}, "PRAGMA index_info("+common.SqlQuoteIdentifier(common.DialectSQLite, index)+")"); err != nil {
+			return nil, err
+		}
+		if len(columns) == 1 {
+			uniques[columns[0]] = true
+		}
+	}
+	return uniques, nil
+}
+
+func (s *SqliteSchemaRepository) query(ctx context.Context, fn func(rows *sql.Rows) error, queryString string) error {
+	rows, err := s.client.QueryContext(ctx, queryString)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -1,6 +1,7 @@
 package infrastructure
 
 import (
+	"database/sql"
 	"fmt"
 	"ifttt/handler/application/config"
 	"ifttt/handler/common"
@@ -9,6 +10,9 @@ import (
 	"ifttt/handler/domain/orm_schema"
 	"ifttt/handler/domain/resolvable"
 	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
 )
 
 type dbStorer interface {
@@ -41,6 +45,8 @@ type ConfigStore struct {
 	CronRepo            api.CronPersistentRepository
 	OrmRepo             orm_schema.PersistentRepository
 	ResponseProfileRepo configuration.ResponseProfilePersistentRepository
+	InternalTagRepo     configuration.InternalTagPersistentRepository
+	EventRepo           configuration.EventPersistentRepository
 }
 
 type CacheStore struct {
@@ -51,8 +57,21 @@ type CacheStore struct {
 }
 
 type DataStore struct {
+	Name         string
+	Timeout      uint
 	Store        dataStorer
 	RawQueryRepo resolvable.RawQueryRepository
+	SchemaRepo   orm_schema.IntrospectionRepository
+}
+
+type dataStoreSettings struct {
+	Timeout uint `json:"timeout" mapstructure:"timeout"`
+}
+
+type connectionPool struct {
+	MaxOpenConns int `json:"maxOpenConns" mapstructure:"maxOpenConns"`
+	MaxIdleConns int `json:"maxIdleConns" mapstructure:"maxIdleConns"`
+	MaxLifeTime  int `json:"maxLifeTime" mapstructure:"maxLifeTime"`
 }
 
 type AppCacheStore struct {
@@ -71,13 +90,34 @@ func NewConfigStore() (*ConfigStore, error) {
 
 func NewDataStore() (*DataStore, error) {
 	connectionSettings := config.GetConfig().GetStringMap(common.EnvData)
-	if store, err := dataStoreFactory(connectionSettings); err != nil {
+	if store, err := dataStoreFactory(common.DataSourceDefault, connectionSettings); err != nil {
 		return nil, err
 	} else {
 		return store, nil
 	}
 }
 
+func NewDataStores(defaultStore *DataStore) (map[string]*DataStore, error) {
+	dataStores := map[string]*DataStore{defaultStore.Name: defaultStore}
+	for name, settings := range config.GetConfig().GetStringMap(common.EnvDataStores) {
+		name = strings.ToLower(name)
+		if _, ok := dataStores[name]; ok {
+			return nil, fmt.Errorf("data store %s already defined", name)
+		}
+
+		connectionSettings, ok := settings.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid settings for data store %s", name)
+		}
+		if store, err := dataStoreFactory(name, connectionSettings); err != nil {
+			return nil, fmt.Errorf("could not init data store %s: %s", name, err)
+		} else {
+			dataStores[name] = store
+		}
+	}
+	return dataStores, nil
+}
+
 func NewCacheStore() (*CacheStore, error) {
 	connectionSettings := config.GetConfig().GetStringMap(common.EnvCache)
 	if store, err := cacheStoreFactory(connectionSettings); err != nil {
@@ -117,7 +157,7 @@ func configStoreFactory(connectionSettings map[string]any) (*ConfigStore, error)
 	return storer.createConfigStore(), nil
 }
 
-func dataStoreFactory(connectionSettings map[string]any) (*DataStore, error) {
+func dataStoreFactory(name string, connectionSettings map[string]any) (*DataStore, error) {
 	var storer dataStorer
 	dbName, ok := connectionSettings[common.EnvDBName]
 	if !ok {
@@ -129,14 +169,37 @@ func dataStoreFactory(connectionSettings map[string]any) (*DataStore, error) {
 		storer = &postgresStore{}
 	case mysqlDb:
 		storer = &mysqlStore{}
+	case sqliteDb:
+		storer = &sqliteStore{}
 	default:
 		return nil, fmt.Errorf("db not found %s", dbName)
 	}
 
+	var settings dataStoreSettings
+	if err := mapstructure.Decode(connectionSettings, &settings); err != nil {
+		return nil, fmt.Errorf("could not decode data store settings: %s", err)
+	}
+
 	if err := storer.init(connectionSettings); err != nil {
 		return nil, err
 	}
-	return storer.createDataStore(), nil
+
+	dataStore := storer.createDataStore()
+	dataStore.Name = name
+	dataStore.Timeout = settings.Timeout
+	return dataStore, nil
+}
+
+func (c *connectionPool) apply(db *sql.DB) {
+	if c.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(c.MaxOpenConns)
+	}
+	if c.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(c.MaxIdleConns)
+	}
+	if c.MaxLifeTime > 0 {
+		db.SetConnMaxLifetime(time.Duration(c.MaxLifeTime) * time.Second)
+	}
 }
 
 func cacheStoreFactory(connectionSettings map[string]any) (*CacheStore, error) {
@@ -19,11 +19,12 @@ type mysqlStore struct {
 }
 
 type mysqlConfig struct {
-	Host     string `json:"host" mapstructure:"host"`
-	Port     string `json:"port" mapstructure:"port"`
-	Database string `json:"database" mapstructure:"database"`
-	Username string `json:"username" mapstructure:"username"`
-	Password string `json:"password" mapstructure:"password"`
+	Host     string         `json:"host" mapstructure:"host"`
+	Port     string         `json:"port" mapstructure:"port"`
+	Database string         `json:"database" mapstructure:"database"`
+	Username string         `json:"username" mapstructure:"username"`
+	Password string         `json:"password" mapstructure:"password"`
+	Pool     connectionPool `json:"pool" mapstructure:"pool"`
 }
 
 func (m *mysqlStore) init(config map[string]any) error {
@@ -37,6 +38,7 @@ func (m *mysqlStore) init(config map[string]any) error {
 	if db, err := sql.Open(mysqlDb, connectionString); err != nil {
 		return err
 	} else {
+		m.config.Pool.apply(db)
 		m.store = db
 	}
 	return nil
@@ -47,5 +49,6 @@ func (m *mysqlStore) createDataStore() *DataStore {
 	return &DataStore{
 		Store:        m,
 		RawQueryRepo: mysqlInfra.NewMySqlRawQueryRepository(mysqlBase),
+		SchemaRepo:   mysqlInfra.NewMySqlSchemaRepository(mysqlBase),
 	}
 }
@@ -3,7 +3,6 @@ package infrastructure
 import (
 	"fmt"
 	postgresInfra "ifttt/handler/infrastructure/postgres"
-	"time"
 
 	"github.com/mitchellh/mapstructure"
 	"gorm.io/driver/postgres"
@@ -18,18 +17,12 @@ type postgresStore struct {
 }
 
 type postgresConfig struct {
-	Host     string       `json:"host" mapstructure:"host"`
-	Port     string       `json:"port" mapstructure:"port"`
-	Database string       `json:"database" mapstructure:"database"`
-	Username string       `json:"username" mapstructure:"username"`
-	Password string       `json:"password" mapstructure:"password"`
-	Pool     postgresPool `json:"pool" mapstructure:"pool"`
-}
-
-type postgresPool struct {
-	MaxOpenConns int `json:"maxOpenConns" mapstructure:"maxOpenConns"`
-	MaxIdleConns int `json:"maxIdleConns" mapstructure:"maxIdleConns"`
-	MaxLifeTime  int `json:"maxLifeTime" mapstructure:"maxLifeTime"`
+	Host     string         `json:"host" mapstructure:"host"`
+	Port     string         `json:"port" mapstructure:"port"`
+	Database string         `json:"database" mapstructure:"database"`
+	Username string         `json:"username" mapstructure:"username"`
+	Password string         `json:"password" mapstructure:"password"`
+	Pool     connectionPool `json:"pool" mapstructure:"pool"`
 }
 
 func (p *postgresStore) init(config map[string]any) error {
@@ -50,19 +43,18 @@ func (p *postgresStore) init(config map[string]any) error {
 		if sqlDb, err := db.DB(); err != nil {
 			return err
 		} else {
-			sqlDb.SetMaxOpenConns(p.config.Pool.MaxOpenConns)
-			sqlDb.SetMaxIdleConns(p.config.Pool.MaxIdleConns)
-			sqlDb.SetConnMaxLifetime(time.Duration(p.config.Pool.MaxLifeTime) * time.Second)
+			p.config.Pool.apply(sqlDb)
 		}
 	}
 	return nil
 }
 
 func (p *postgresStore) createDataStore() *DataStore {
-	// postgresBase := postgresInfra.NewPostgresBaseRepository(p.store, false)
+	postgresBase := postgresInfra.NewPostgresBaseRepository(p.store, false)
 	return &DataStore{
-		Store: p,
-		// RawQueryRepo: postgresInfra.NewPostgresRawQueryRepository(postgresBase),
+		Store:        p,
+		RawQueryRepo: postgresInfra.NewPostgresRawQueryRepository(postgresBase),
+		SchemaRepo:   postgresInfra.NewPostgresSchemaRepository(postgresBase),
 	}
 }
 
@@ -74,6 +66,7 @@ func (p *postgresStore) createConfigStore() *ConfigStore {
 		CronRepo:            postgresInfra.NewPostgresCronRepository(postgresBase),
 		OrmRepo:             postgresInfra.NewPostgresOrmRepository(postgresBase),
 		ResponseProfileRepo: postgresInfra.NewPostgresResponseProfilesRepository(postgresBase),
-		// InternalTagRepo:     postgresInfra.NewPostgresInternalTagRepository(postgresBase),
+		InternalTagRepo:     postgresInfra.NewPostgresInternalTagRepository(postgresBase),
+		EventRepo:           postgresInfra.NewPostgresEventRepository(postgresBase),
 	}
 }
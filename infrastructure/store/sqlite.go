@@ -0,0 +1,55 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+
+	sqliteInfra "ifttt/handler/infrastructure/sqlite"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+const sqliteDb = "sqlite"
+
+type sqliteStore struct {
+	store  *sql.DB
+	config sqliteConfig
+}
+
+type sqliteConfig struct {
+	Path        string         `json:"path" mapstructure:"path"`
+	BusyTimeout uint           `json:"busyTimeout" mapstructure:"busyTimeout"`
+	Pool        connectionPool `json:"pool" mapstructure:"pool"`
+}
+
+func (s *sqliteStore) init(config map[string]any) error {
+	if err := mapstructure.Decode(config, &s.config); err != nil {
+		return fmt.Errorf("method: *sqliteStore.Init: could not decode configuration from env: %s", err)
+	} else if s.config.Path == "" {
+		return fmt.Errorf("method: *sqliteStore.Init: path not found in env")
+	}
+	if s.config.BusyTimeout == 0 {
+		s.config.BusyTimeout = 5000
+	}
+	connectionString := fmt.Sprintf(
+		"file:%s?_pragma=busy_timeout(%d)&_pragma=foreign_keys(1)", s.config.Path, s.config.BusyTimeout,
+	)
+	if db, err := sql.Open(sqliteDb, connectionString); err != nil {
+		return err
+	} else {
+		s.config.Pool.apply(db)
+		s.store = db
+	}
+	return nil
+}
+
+func (s *sqliteStore) createDataStore() *DataStore {
+	sqliteBase := sqliteInfra.NewSqliteBaseRepository(s.store)
+	return &DataStore{
+		Store:        s,
+		RawQueryRepo: sqliteInfra.NewSqliteRawQueryRepository(sqliteBase),
+		SchemaRepo:   sqliteInfra.NewSqliteSchemaRepository(sqliteBase),
+	}
+}
@@ -4,9 +4,19 @@ import (
 	"fmt"
 	"ifttt/handler/application"
 	"ifttt/handler/application/config"
+	"os"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		config.Init()
+		if err := application.RunCommand(os.Args[1:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	fmt.Println("Starting handler")
 	config.Init()
 	application.Init()